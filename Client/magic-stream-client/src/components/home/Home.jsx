@@ -16,8 +16,8 @@ const Home =({updateMovieReview}) => {
                 console.log('Fetching movies from:', axiosClient.defaults.baseURL + '/movies');
                 const response = await axiosClient.get('/movies');
                 console.log('Movies response:', response.data);
-                setMovies(response.data);
-                if (response.data.length === 0){
+                setMovies(response.data.items);
+                if (response.data.items.length === 0){
                     setMessage('There are currently no movies available')
                 }
 
@@ -0,0 +1,128 @@
+package ai
+
+import (
+	"context"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// negationFactor flips and dampens a negated sentiment word: "not great" is
+// mildly negative rather than the opposite of "great".
+const negationFactor = -0.5
+
+var sentimentLexicon = map[string]float64{
+	"amazing": 1, "brilliant": 1, "excellent": 1, "fantastic": 1, "masterpiece": 1,
+	"sublime": 1, "superb": 1, "best": 1, "perfect": 1, "wonderful": 1, "outstanding": 1,
+	"love": 0.9, "loved": 0.9, "lovely": 0.9, "beautiful": 0.8, "great": 0.8,
+	"enjoyed": 0.7, "enjoyable": 0.7, "fun": 0.6, "good": 0.6, "like": 0.6, "liked": 0.6,
+	"nice": 0.5, "decent": 0.3, "fine": 0.2,
+	"okay": 0, "ok": 0, "average": 0,
+	"mediocre": -0.3, "meh": -0.3, "dull": -0.5, "bad": -0.6, "boring": -0.6,
+	"disappointing": -0.6, "poor": -0.6, "weak": -0.5,
+	"hate": -0.9, "hated": -0.9, "awful": -1, "aweful": -1, "terrible": -1,
+	"horrible": -1, "worst": -1, "garbage": -1, "dreadful": -1,
+}
+
+var intensifiers = map[string]float64{
+	"absolutely": 1.3, "extremely": 1.4, "incredibly": 1.3, "really": 1.2,
+	"very": 1.2, "so": 1.1, "too": 1.1, "truly": 1.2,
+}
+
+var negations = map[string]bool{
+	"not": true, "no": true, "never": true, "hardly": true, "nor": true, "neither": true,
+	"didn't": true, "don't": true, "doesn't": true, "wasn't": true, "isn't": true,
+	"aren't": true, "weren't": true, "can't": true, "couldn't": true, "won't": true,
+}
+
+// clauseBreakers end the scope of a pending negation or intensifier.
+var clauseBreakers = map[string]bool{"but": true, "although": true, "though": true, "however": true}
+
+// LexiconClassifier ranks reviews with a fixed sentiment word list. It is
+// deterministic and needs no network access.
+type LexiconClassifier struct{}
+
+func NewLexiconClassifier() LexiconClassifier {
+	return LexiconClassifier{}
+}
+
+// lexiconWords lower-cases text and splits it into words, keeping apostrophes
+// so contractions such as "didn't" survive. Sentence punctuation is returned
+// as "." so it can end a clause.
+func lexiconWords(text string) []string {
+	text = strings.ReplaceAll(strings.ToLower(text), "’", "'")
+
+	var words []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			words = append(words, current.String())
+			current.Reset()
+		}
+	}
+	for _, r := range text {
+		switch {
+		case unicode.IsLetter(r) || r == '\'':
+			current.WriteRune(r)
+		case strings.ContainsRune(".,!?;:", r):
+			flush()
+			words = append(words, ".")
+		default:
+			flush()
+		}
+	}
+	flush()
+	return words
+}
+
+// Score returns the sentiment of text between -1 (negative) and 1 (positive).
+// Text without any sentiment words scores 0.
+func (LexiconClassifier) Score(text string) float64 {
+	var total float64
+	var count int
+
+	negated := false
+	multiplier := 1.0
+	for _, word := range lexiconWords(text) {
+		if word == "." || clauseBreakers[word] {
+			negated, multiplier = false, 1.0
+			continue
+		}
+		if negations[word] {
+			negated = true
+			continue
+		}
+		if boost, ok := intensifiers[word]; ok {
+			multiplier *= boost
+			continue
+		}
+		value, ok := sentimentLexicon[word]
+		if !ok {
+			continue
+		}
+
+		value = math.Max(-1, math.Min(1, value*multiplier))
+		if negated {
+			value *= negationFactor
+		}
+		total += value
+		count++
+		negated, multiplier = false, 1.0
+	}
+
+	if count == 0 {
+		return 0
+	}
+	return total / float64(count)
+}
+
+// Classify spreads the sentiment range evenly over the rankings, so with the
+// five seed rankings a score of 1 is Excellent, 0 is Okay and -1 is Terrible.
+func (l LexiconClassifier) Classify(ctx context.Context, review string, rankings []string) (string, error) {
+	if len(rankings) == 0 {
+		return "", ErrNoRanking
+	}
+	score := l.Score(review)
+	index := int(math.Round((1 - score) / 2 * float64(len(rankings)-1)))
+	return rankings[index], nil
+}
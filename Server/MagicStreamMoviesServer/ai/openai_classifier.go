@@ -0,0 +1,108 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	defaultOpenAIBaseURL = "https://api.openai.com/v1"
+	defaultOpenAIModel   = "gpt-4o-mini"
+)
+
+// OpenAIClassifier asks an OpenAI-compatible chat completions endpoint to pick
+// the ranking. BaseURL can point at any server that speaks the same API, such
+// as a local model or a test stub.
+type OpenAIClassifier struct {
+	BaseURL    string
+	APIKey     string
+	Model      string
+	HTTPClient *http.Client
+}
+
+func NewOpenAIClassifier(baseURL, apiKey, model string) *OpenAIClassifier {
+	if baseURL == "" {
+		baseURL = defaultOpenAIBaseURL
+	}
+	if model == "" {
+		model = defaultOpenAIModel
+	}
+	return &OpenAIClassifier{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		APIKey:     apiKey,
+		Model:      model,
+		HTTPClient: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	Temperature float64       `json:"temperature"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+func (o *OpenAIClassifier) Classify(ctx context.Context, review string, rankings []string) (string, error) {
+	prompt := fmt.Sprintf(
+		"Classify the sentiment of the following movie review. Answer with exactly one of these words and nothing else: %s.\n\nReview: %s",
+		strings.Join(rankings, ", "), review,
+	)
+	body, err := json.Marshal(chatRequest{
+		Model: o.Model,
+		Messages: []chatMessage{
+			{Role: "system", Content: "You rank movie reviews for a streaming catalogue."},
+			{Role: "user", Content: prompt},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.BaseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if o.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.APIKey)
+	}
+
+	resp, err := o.HTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("chat completions returned %s", resp.Status)
+	}
+
+	var completion chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&completion); err != nil {
+		return "", err
+	}
+	if len(completion.Choices) == 0 {
+		return "", ErrNoRanking
+	}
+
+	name, ok := matchRanking(completion.Choices[0].Message.Content, rankings)
+	if !ok {
+		return "", fmt.Errorf("%w: model answered %q", ErrNoRanking, completion.Choices[0].Message.Content)
+	}
+	return name, nil
+}
@@ -0,0 +1,83 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/config"
+	models "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+)
+
+// NotRankedValue is the ranking value of movies that have not been ranked.
+const NotRankedValue = 999
+
+// DefaultRankings mirrors MagicStreamSeedData/rankings.json, best first, and is
+// used when the rankings collection is empty.
+var DefaultRankings = []models.Ranking{
+	{RankingValue: 1, RankingName: "Excellent"},
+	{RankingValue: 2, RankingName: "Good"},
+	{RankingValue: 3, RankingName: "Okay"},
+	{RankingValue: 4, RankingName: "Bad"},
+	{RankingValue: 5, RankingName: "Terrible"},
+}
+
+// ErrNoRanking is returned when a classifier cannot map a review to any of the
+// offered rankings.
+var ErrNoRanking = errors.New("review could not be mapped to a ranking")
+
+// ReviewClassifier maps the text of an admin review to one of the given
+// ranking names. Rankings are ordered best first.
+type ReviewClassifier interface {
+	Classify(ctx context.Context, review string, rankings []string) (string, error)
+}
+
+// matchRanking finds the ranking name an answer consists of, ignoring case
+// and surrounding whitespace and punctuation. Anything more, such as "not
+// okay", matches nothing rather than the name it contains.
+func matchRanking(answer string, rankings []string) (string, bool) {
+	answer = strings.Trim(strings.TrimSpace(answer), " .!\"'`")
+	for _, name := range rankings {
+		if strings.EqualFold(answer, name) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// FallbackClassifier tries Primary and, if it fails, answers with Secondary.
+type FallbackClassifier struct {
+	Primary   ReviewClassifier
+	Secondary ReviewClassifier
+}
+
+func (f FallbackClassifier) Classify(ctx context.Context, review string, rankings []string) (string, error) {
+	name, err := f.Primary.Classify(ctx, review, rankings)
+	if err == nil {
+		return name, nil
+	}
+	log.Println("Warning: review classifier failed, using fallback:", err)
+	return f.Secondary.Classify(ctx, review, rankings)
+}
+
+// NewReviewClassifier selects a classifier from cfg.Backend ("openai" or
+// "lexicon"). When unset, the OpenAI backend is used if an API key is
+// configured. The OpenAI backend always falls back to the lexicon so reviews
+// can still be ranked when the API is unreachable.
+func NewReviewClassifier(cfg config.Classifier) ReviewClassifier {
+	lexicon := NewLexiconClassifier()
+
+	backend := cfg.Backend
+	if backend == "" && cfg.OpenAIAPIKey != "" {
+		backend = "openai"
+	}
+
+	if backend != "openai" {
+		return lexicon
+	}
+	return FallbackClassifier{
+		Primary:   NewOpenAIClassifier(cfg.OpenAIBaseURL, cfg.OpenAIAPIKey, cfg.OpenAIModel),
+		Secondary: lexicon,
+	}
+}
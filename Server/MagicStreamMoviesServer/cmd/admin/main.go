@@ -0,0 +1,167 @@
+// Command admin manages administrator accounts in MongoDB. Registration only
+// ever creates USER accounts, so the first admin is made here; later ones can
+// also be promoted by an existing admin through PATCH /admin/users/:user_id.
+//
+//	go run ./cmd/admin create --email a@example.com --first-name Ada --last-name Lovelace [--config file]
+//	go run ./cmd/admin promote --email a@example.com [--config file]
+//
+// create reads the new password from $ADMIN_PASSWORD, or else from the first
+// line of standard input, so it does not end up in the shell history.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/config"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/database"
+	models "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// newAdmin holds the create flags, checked like a registration.
+type newAdmin struct {
+	FirstName string `validate:"required,min=2,max=100"`
+	LastName  string `validate:"required,min=2,max=100"`
+	Email     string `validate:"required,email"`
+	Password  string `validate:"required,min=6"`
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: admin create --email EMAIL --first-name NAME --last-name NAME [--config file]")
+	fmt.Fprintln(os.Stderr, "       admin promote --email EMAIL [--config file]")
+	os.Exit(2)
+}
+
+// readPassword returns $ADMIN_PASSWORD or the first line of standard input.
+func readPassword() (string, error) {
+	if password := os.Getenv("ADMIN_PASSWORD"); password != "" {
+		return password, nil
+	}
+	fmt.Fprint(os.Stderr, "Password: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("read password: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// create inserts an active admin whose email counts as verified; the
+// operator running the command vouches for it.
+func create(ctx context.Context, users repository.UserRepository, admin newAdmin) (models.User, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(admin.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	now := time.Now()
+	user := models.User{
+		UserID:          primitive.NewObjectID().Hex(),
+		FirstName:       admin.FirstName,
+		LastName:        admin.LastName,
+		Email:           admin.Email,
+		Password:        string(hashed),
+		Role:            "ADMIN",
+		Status:          models.UserActive,
+		FavouriteGenres: []models.Genre{},
+		EmailVerified:   true,
+		EmailVerifiedAt: &now,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := users.Insert(ctx, &user); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return models.User{}, fmt.Errorf("%s already has an account; use promote instead", admin.Email)
+		}
+		return models.User{}, err
+	}
+	return user, nil
+}
+
+// promote makes an existing account an active admin. Tokens the user holds
+// keep their old role until they expire or the user logs in again.
+func promote(ctx context.Context, users repository.UserRepository, email string) (models.User, error) {
+	user, err := users.GetByEmail(ctx, email)
+	if err == nil && user.AccountStatus() == models.UserDeleted {
+		err = repository.ErrNotFound
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		return models.User{}, fmt.Errorf("%s has no account", email)
+	}
+	if err != nil {
+		return models.User{}, err
+	}
+
+	role, status := "ADMIN", models.UserActive
+	return users.Update(ctx, user.UserID, repository.UserChanges{Role: &role, Status: &status}, time.Now())
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	command := os.Args[1]
+
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	email := flags.String("email", "", "email address of the admin")
+	firstName := flags.String("first-name", "", "first name of the new admin")
+	lastName := flags.String("last-name", "", "last name of the new admin")
+	configFile := flags.String("config", "", "YAML or TOML config file; defaults to $CONFIG_FILE")
+
+	switch command {
+	case "create", "promote":
+		_ = flags.Parse(os.Args[2:])
+	default:
+		usage()
+	}
+	if *email == "" {
+		usage()
+	}
+
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var admin newAdmin
+	if command == "create" {
+		admin = newAdmin{FirstName: *firstName, LastName: *lastName, Email: *email}
+		admin.Password, err = readPassword()
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := validator.New().Struct(admin); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	client := database.Connect(cfg.Mongo.URI)
+	defer func() {
+		_ = client.Disconnect(context.Background())
+	}()
+	users := repository.NewMongoStore(client.Database(cfg.Mongo.Database)).Users
+
+	var user models.User
+	if command == "create" {
+		user, err = create(ctx, users, admin)
+	} else {
+		user, err = promote(ctx, users, *email)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%s is an admin (user_id %s)\n", user.Email, user.UserID)
+}
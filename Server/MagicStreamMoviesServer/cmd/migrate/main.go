@@ -0,0 +1,100 @@
+// Command migrate applies or reverts the MongoDB schema migrations. The
+// server applies pending ones itself on start unless mongo.migrate is off.
+//
+//	go run ./cmd/migrate status [--config file]
+//	go run ./cmd/migrate up [--config file]
+//	go run ./cmd/migrate down [--steps n] [--config file]
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/config"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/database"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/migrations"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: migrate status|up|down [--steps n] [--config file]")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	command := os.Args[1]
+
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	steps := flags.Int("steps", 1, "with down, how many migrations to revert")
+	configFile := flags.String("config", "", "YAML or TOML config file; defaults to $CONFIG_FILE")
+
+	switch command {
+	case "status", "up", "down":
+		_ = flags.Parse(os.Args[2:])
+	default:
+		usage()
+	}
+	if *steps < 1 {
+		log.Fatal("--steps must be at least 1")
+	}
+
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	client := database.Connect(cfg.Mongo.URI)
+	defer func() {
+		_ = client.Disconnect(context.Background())
+	}()
+	db := client.Database(cfg.Mongo.Database)
+
+	switch command {
+	case "status":
+		states, err := migrations.Status(ctx, db)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, state := range states {
+			applied := "pending"
+			if state.AppliedAt != nil {
+				applied = "applied " + state.AppliedAt.Local().Format(time.RFC3339)
+			}
+			if state.Up == nil {
+				applied += " (unknown to this build)"
+			}
+			fmt.Printf("%4d  %-32s %s\n", state.Version, state.Name, applied)
+		}
+	case "up":
+		done, err := migrations.Up(ctx, db)
+		for _, migration := range done {
+			fmt.Printf("Applied %d %s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(done) == 0 {
+			fmt.Println("Nothing to apply")
+		}
+	case "down":
+		done, err := migrations.Down(ctx, db, *steps)
+		for _, migration := range done {
+			fmt.Printf("Reverted %d %s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(done) == 0 {
+			fmt.Println("Nothing to revert")
+		}
+	}
+}
@@ -0,0 +1,201 @@
+// Command seed loads the JSON fixtures in MagicStreamSeedData into MongoDB.
+//
+// Every record is validated against the models struct tags and upserted on its
+// natural key, so running the command twice leaves the database unchanged:
+//
+//	go run ./cmd/seed --dir ../../MagicStreamSeedData [--drop] [--dry-run] [--config file]
+//
+// The files may use MongoDB extended JSON such as {"$date": "..."}.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/config"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/database"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/fixtures"
+	models "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var validate = validator.New()
+
+// record is one fixture ready to be written, or the reason it cannot be.
+type record struct {
+	doc bson.M
+	key interface{}
+	err error
+}
+
+// seedFile describes how one fixture file maps onto a collection.
+type seedFile struct {
+	file       string
+	collection string
+	key        string
+	load       func(data []byte, key string) ([]record, error)
+}
+
+var seedFiles = []seedFile{
+	{file: "genres.json", collection: "genres", key: "genre_id", load: loadRecords[models.Genre]},
+	{file: "rankings.json", collection: "rankings", key: "ranking_value", load: loadRecords[models.Ranking]},
+	{file: "movies.json", collection: "movies", key: "imdb_id", load: loadRecords[models.Movie]},
+	{file: "users.json", collection: "users", key: "email", load: loadRecords[models.User]},
+}
+
+// counts tallies the outcome of loading one file.
+type counts struct {
+	records, inserted, updated, unchanged, invalid int
+}
+
+// loadRecords parses an extended JSON array and validates each element as a
+// T. The raw documents are kept for writing so only the fields present in
+// the file are set.
+func loadRecords[T any](data []byte, key string) ([]record, error) {
+	items, err := fixtures.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]record, len(items))
+	for i, item := range items {
+		var typed T
+		if err := bson.Unmarshal(item, &typed); err != nil {
+			records[i].err = err
+			continue
+		}
+		if err := validate.Struct(typed); err != nil {
+			records[i].err = err
+			continue
+		}
+
+		var doc bson.M
+		if err := bson.Unmarshal(item, &doc); err != nil {
+			records[i].err = err
+			continue
+		}
+		delete(doc, "_id")
+
+		value, ok := doc[key]
+		if !ok {
+			records[i].err = fmt.Errorf("missing key %q", key)
+			continue
+		}
+		records[i] = record{doc: doc, key: value}
+	}
+	return records, nil
+}
+
+func seed(ctx context.Context, db *mongo.Database, dir string, file seedFile, drop, dryRun bool) (counts, error) {
+	var result counts
+
+	data, err := os.ReadFile(filepath.Join(dir, file.file))
+	if err != nil {
+		return result, err
+	}
+	records, err := file.load(data, file.key)
+	if err != nil {
+		return result, fmt.Errorf("parse %s: %w", file.file, err)
+	}
+	result.records = len(records)
+
+	collection := database.OpenCollection(file.collection, db)
+	if drop && !dryRun {
+		// delete the documents rather than dropping the collection, so the
+		// indexes the migrations created stay in place
+		if _, err := collection.DeleteMany(ctx, bson.M{}); err != nil {
+			return result, err
+		}
+	}
+
+	for i, rec := range records {
+		if rec.err != nil {
+			result.invalid++
+			log.Printf("%s[%d]: %v", file.file, i, rec.err)
+			continue
+		}
+		filter := bson.M{file.key: rec.key}
+
+		if dryRun {
+			existing := int64(0)
+			if !drop {
+				existing, err = collection.CountDocuments(ctx, filter)
+				if err != nil {
+					return result, err
+				}
+			}
+			if existing > 0 {
+				result.updated++
+			} else {
+				result.inserted++
+			}
+			continue
+		}
+
+		outcome, err := collection.UpdateOne(ctx, filter, bson.M{"$set": rec.doc}, options.Update().SetUpsert(true))
+		if err != nil {
+			return result, fmt.Errorf("%s[%d]: %w", file.file, i, err)
+		}
+		switch {
+		case outcome.UpsertedCount > 0:
+			result.inserted++
+		case outcome.ModifiedCount > 0:
+			result.updated++
+		default:
+			result.unchanged++
+		}
+	}
+	return result, nil
+}
+
+func main() {
+	dir := flag.String("dir", "../../MagicStreamSeedData", "directory containing the seed JSON files")
+	drop := flag.Bool("drop", false, "empty each collection before loading it")
+	dryRun := flag.Bool("dry-run", false, "validate and report what would change without writing")
+	configFile := flag.String("config", "", "YAML or TOML config file; defaults to $CONFIG_FILE")
+	flag.Parse()
+
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	client := database.Connect(cfg.Mongo.URI)
+	defer func() {
+		_ = client.Disconnect(context.Background())
+	}()
+
+	if *dryRun {
+		fmt.Println("Dry run: no changes will be written")
+	}
+
+	failed := false
+	for _, file := range seedFiles {
+		result, err := seed(ctx, client.Database(cfg.Mongo.Database), *dir, file, *drop, *dryRun)
+		if err != nil {
+			log.Printf("%s: %v", file.file, err)
+			failed = true
+			continue
+		}
+		if result.invalid > 0 {
+			failed = true
+		}
+		fmt.Printf("%-14s %3d records: %3d inserted, %3d updated, %3d unchanged, %3d invalid\n",
+			file.file, result.records, result.inserted, result.updated, result.unchanged, result.invalid)
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,352 @@
+// Package config holds the server settings. They are read once at start up
+// by Load and handed to whatever needs them, rather than each package
+// reading the environment on its own.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	Development = "development"
+	Production  = "production"
+)
+
+// Config is the complete server configuration.
+type Config struct {
+	// Environment is Development or Production.
+	Environment string
+	// Storage is "mongo" or "memory".
+	Storage        string
+	Server         Server
+	Mongo          Mongo
+	Auth           Auth
+	RateLimit      RateLimit
+	Login          Login
+	Account        Account
+	Mail           Mail
+	Classifier     Classifier
+	Recommendation Recommendation
+}
+
+// Server configures the HTTP listener.
+type Server struct {
+	Port int
+	// RequestTimeout bounds the work done for a single request.
+	RequestTimeout time.Duration
+	// ReadTimeout, WriteTimeout and IdleTimeout are the http.Server limits
+	// for reading a request, writing its response and keeping an idle
+	// connection open.
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+	// DrainDelay is how long the server keeps accepting requests, with
+	// /readyz failing, after a shutdown signal so load balancers notice.
+	DrainDelay time.Duration
+	// ShutdownTimeout is how long in-flight requests then get to finish.
+	ShutdownTimeout time.Duration
+	// AllowedOrigins are the origins CORS lets call the API with credentials.
+	AllowedOrigins []string
+	// TrustedProxies are the IPs or CIDR ranges of the reverse proxies in
+	// front of the server. Only their X-Forwarded-For and X-Real-IP headers
+	// are believed; by default there are none and the client IP is the
+	// connection's peer. See RateLimit for why this matters.
+	TrustedProxies []string
+}
+
+// Addr is the listen address for Port.
+func (s Server) Addr() string {
+	return fmt.Sprintf(":%d", s.Port)
+}
+
+// Mongo locates the database.
+type Mongo struct {
+	URI      string
+	Database string
+	// Migrate applies pending schema migrations on start. With it off the
+	// server only warns about them; run cmd/migrate instead.
+	Migrate bool
+}
+
+// Auth configures how tokens are signed and how long they last. See
+// jwks.Options for how the signing keys are chosen.
+type Auth struct {
+	// SigningAlgorithm is RS256 or EdDSA.
+	SigningAlgorithm string
+	KeyDir           string
+	SigningKeyID     string
+	RetiringKeyIDs   []string
+	// GenerateKeys creates a signing key in KeyDir on first start. Every
+	// instance needs the same keys, so production deployments have to
+	// provision them instead.
+	GenerateKeys    bool
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+}
+
+// RateLimit configures request throttling. Each policy is a token bucket
+// per client: anonymous clients are told apart by IP and signed-in ones by
+// user ID.
+//
+// The client IP, which the login throttling also counts failures by, comes
+// from the forwarding headers only when the request arrives through one of
+// Server.TrustedProxies. Behind a proxy, list it there, or every client
+// shares the proxy's bucket; never list addresses clients can connect from,
+// or they can pick a fresh IP for every request.
+type RateLimit struct {
+	Enabled bool
+	// Shared keeps the buckets in MongoDB so every instance draws from the
+	// same ones. Otherwise each instance limits on its own, which lets a
+	// client make the limit times the number of instances.
+	Shared bool
+	// Auth covers the endpoints that check or mail credentials, such as
+	// /login, /register and /refresh.
+	Auth RateLimitPolicy
+	// Public covers the anonymous catalogue endpoints such as /movies.
+	Public RateLimitPolicy
+	// API covers every authenticated endpoint.
+	API RateLimitPolicy
+}
+
+// RateLimitPolicy allows Limit requests per Window. A client may spend the
+// whole limit in a burst, after which the bucket refills evenly over the
+// window. It is written as "limit/window", such as "10/1m".
+type RateLimitPolicy struct {
+	Limit  int
+	Window time.Duration
+}
+
+func (p RateLimitPolicy) String() string {
+	return fmt.Sprintf("%d/%s", p.Limit, p.Window)
+}
+
+// Login configures the brute-force protection of /login. Failures are
+// counted per email address and per client IP. Past the free attempts each
+// further failure doubles the wait before the next attempt, starting at
+// BackoffBase and capped at BackoffMax. LockoutThreshold failures for one
+// address lock the account for LockoutDuration. A counter is forgotten
+// FailureWindow after its last failure.
+type Login struct {
+	FreeAttempts     int
+	IPFreeAttempts   int
+	BackoffBase      time.Duration
+	BackoffMax       time.Duration
+	FailureWindow    time.Duration
+	LockoutThreshold int
+	LockoutDuration  time.Duration
+}
+
+// Account configures email verification and password resets.
+type Account struct {
+	// RequireVerifiedEmail refuses logins until the user has followed the
+	// link in their verification email.
+	RequireVerifiedEmail bool
+	VerificationTTL      time.Duration
+	ResetTTL             time.Duration
+	// VerifyURL is the link mailed for verification, normally the server's
+	// GET /verify-email. ResetURL is the client page that asks for the new
+	// password and posts it to /password/reset. Both get ?token= appended.
+	VerifyURL string
+	ResetURL  string
+}
+
+// Mail selects how emails are sent. Backend "log" writes them to the log,
+// "file" saves them as .eml files in OutboxDir and "smtp" hands them to
+// SMTPHost.
+type Mail struct {
+	Backend      string
+	From         string
+	OutboxDir    string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+}
+
+// Classifier selects the review classifier. An empty Backend picks OpenAI
+// when an API key is set and the lexicon otherwise.
+type Classifier struct {
+	Backend       string
+	OpenAIAPIKey  string
+	OpenAIBaseURL string
+	OpenAIModel   string
+}
+
+// Recommendation configures the recommender.
+type Recommendation struct {
+	// ColdStart is a recommendation.ColdStartStrategy name.
+	ColdStart string
+}
+
+// Default returns the configuration used for anything left unset: a local
+// MongoDB, the Vite and React dev servers as CORS origins and an Ed25519
+// signing key generated in ./keys. Emails are only logged.
+func Default() Config {
+	return Config{
+		Environment: Development,
+		Storage:     "mongo",
+		Server: Server{
+			Port:            8080,
+			RequestTimeout:  100 * time.Second,
+			ReadTimeout:     15 * time.Second,
+			WriteTimeout:    105 * time.Second,
+			IdleTimeout:     120 * time.Second,
+			DrainDelay:      5 * time.Second,
+			ShutdownTimeout: 30 * time.Second,
+			AllowedOrigins: []string{
+				"http://localhost:5173", // Vite
+				"http://localhost:5174",
+				"http://localhost:3000", // React
+				"http://localhost:8081",
+			},
+		},
+		Mongo: Mongo{
+			URI:      "mongodb://localhost:27017",
+			Database: "MagicStreamMovies",
+			Migrate:  true,
+		},
+		Auth: Auth{
+			SigningAlgorithm: "EdDSA",
+			KeyDir:           "keys",
+			GenerateKeys:     true,
+			AccessTokenTTL:   24 * time.Hour,
+			RefreshTokenTTL:  7 * 24 * time.Hour,
+		},
+		RateLimit: RateLimit{
+			Enabled: true,
+			Auth:    RateLimitPolicy{Limit: 10, Window: time.Minute},
+			Public:  RateLimitPolicy{Limit: 120, Window: time.Minute},
+			API:     RateLimitPolicy{Limit: 300, Window: time.Minute},
+		},
+		Login: Login{
+			FreeAttempts:     5,
+			IPFreeAttempts:   20,
+			BackoffBase:      time.Second,
+			BackoffMax:       5 * time.Minute,
+			FailureWindow:    time.Hour,
+			LockoutThreshold: 10,
+			LockoutDuration:  30 * time.Minute,
+		},
+		Account: Account{
+			VerificationTTL: 48 * time.Hour,
+			ResetTTL:        time.Hour,
+			VerifyURL:       "http://localhost:8080/verify-email",
+			ResetURL:        "http://localhost:5173/reset-password",
+		},
+		Mail: Mail{
+			Backend:   "log",
+			From:      "MagicStream <no-reply@magicstream.local>",
+			OutboxDir: "outbox",
+			SMTPPort:  587,
+		},
+		Recommendation: Recommendation{
+			ColdStart: "top_ranked",
+		},
+	}
+}
+
+// IsProduction reports whether the server runs in production mode.
+func (c *Config) IsProduction() bool {
+	return c.Environment == Production
+}
+
+// Validate reports every setting that is out of range, joined into one
+// error.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Environment == Development || c.Environment == Production,
+		"environment %q must be %s or %s", c.Environment, Development, Production)
+	check(c.Storage == "mongo" || c.Storage == "memory", "storage %q must be mongo or memory", c.Storage)
+
+	check(c.Server.Port > 0 && c.Server.Port < 65536, "server port %d is out of range", c.Server.Port)
+	check(c.Server.RequestTimeout > 0, "server request timeout must be positive")
+	check(c.Server.ReadTimeout > 0, "server read timeout must be positive")
+	check(c.Server.WriteTimeout > c.Server.RequestTimeout, "server write timeout must be longer than the request timeout")
+	check(c.Server.IdleTimeout > 0, "server idle timeout must be positive")
+	check(c.Server.DrainDelay >= 0, "server drain delay must not be negative")
+	check(c.Server.ShutdownTimeout > 0, "server shutdown timeout must be positive")
+	for _, origin := range c.Server.AllowedOrigins {
+		check(origin == "*" || strings.HasPrefix(origin, "http://") || strings.HasPrefix(origin, "https://"),
+			"allowed origin %q must be an http(s) URL", origin)
+	}
+	for _, proxy := range c.Server.TrustedProxies {
+		_, _, cidrErr := net.ParseCIDR(proxy)
+		check(cidrErr == nil || net.ParseIP(proxy) != nil, "trusted proxy %q must be an IP address or CIDR range", proxy)
+	}
+
+	if c.Storage == "mongo" {
+		check(c.Mongo.URI != "", "mongo uri is required")
+		check(c.Mongo.Database != "", "mongo database is required")
+	}
+
+	check(c.Auth.SigningAlgorithm == "RS256" || c.Auth.SigningAlgorithm == "EdDSA",
+		"auth signing algorithm %q must be RS256 or EdDSA", c.Auth.SigningAlgorithm)
+	check(c.Auth.KeyDir != "", "auth key dir is required")
+	check(c.Auth.SigningKeyID == "" || !slices.Contains(c.Auth.RetiringKeyIDs, c.Auth.SigningKeyID),
+		"auth signing key %q cannot also be retiring", c.Auth.SigningKeyID)
+	check(c.Auth.AccessTokenTTL > 0, "auth access token ttl must be positive")
+	check(c.Auth.RefreshTokenTTL >= c.Auth.AccessTokenTTL, "auth refresh token ttl must not be shorter than the access token ttl")
+	check(!c.IsProduction() || !c.Auth.GenerateKeys, "auth generate keys must be off in production")
+
+	if c.RateLimit.Enabled {
+		for name, policy := range map[string]RateLimitPolicy{"auth": c.RateLimit.Auth, "public": c.RateLimit.Public, "api": c.RateLimit.API} {
+			check(policy.Limit > 0 && policy.Window > 0, "rate limit %s policy %s must have a positive limit and window", name, policy)
+		}
+	}
+	check(!c.RateLimit.Shared || c.Storage == "mongo", "rate limit shared needs mongo storage")
+
+	check(c.Login.FreeAttempts > 0, "login free attempts must be positive")
+	check(c.Login.IPFreeAttempts >= c.Login.FreeAttempts, "login ip free attempts must not be fewer than the free attempts per address")
+	check(c.Login.BackoffBase > 0, "login backoff base must be positive")
+	check(c.Login.BackoffMax >= c.Login.BackoffBase, "login backoff max must not be shorter than the backoff base")
+	check(c.Login.LockoutThreshold > c.Login.FreeAttempts, "login lockout threshold must be more than the free attempts")
+	check(c.Login.LockoutDuration > 0, "login lockout duration must be positive")
+	check(c.Login.FailureWindow >= c.Login.LockoutDuration && c.Login.FailureWindow >= c.Login.BackoffMax,
+		"login failure window must not be shorter than the lockout duration or the backoff max")
+
+	check(c.Account.VerificationTTL > 0, "account verification ttl must be positive")
+	check(c.Account.ResetTTL > 0, "account reset ttl must be positive")
+	check(strings.HasPrefix(c.Account.VerifyURL, "http://") || strings.HasPrefix(c.Account.VerifyURL, "https://"),
+		"account verify url %q must be an http(s) URL", c.Account.VerifyURL)
+	check(strings.HasPrefix(c.Account.ResetURL, "http://") || strings.HasPrefix(c.Account.ResetURL, "https://"),
+		"account reset url %q must be an http(s) URL", c.Account.ResetURL)
+
+	check(c.Mail.From != "", "mail from address is required")
+	switch c.Mail.Backend {
+	case "log":
+	case "file":
+		check(c.Mail.OutboxDir != "", "mail backend file needs an outbox dir")
+	case "smtp":
+		check(c.Mail.SMTPHost != "", "mail backend smtp needs a host")
+		check(c.Mail.SMTPPort > 0 && c.Mail.SMTPPort < 65536, "mail smtp port %d is out of range", c.Mail.SMTPPort)
+	default:
+		check(false, "mail backend %q must be log, file or smtp", c.Mail.Backend)
+	}
+	check(!c.IsProduction() || c.Mail.Backend == "smtp", "mail backend must be smtp in production")
+
+	switch c.Classifier.Backend {
+	case "", "openai", "lexicon":
+	default:
+		check(false, "classifier backend %q must be openai or lexicon", c.Classifier.Backend)
+	}
+	check(c.Classifier.Backend != "openai" || c.Classifier.OpenAIAPIKey != "", "classifier backend openai needs an api key")
+
+	switch c.Recommendation.ColdStart {
+	case "top_ranked", "newest", "none":
+	default:
+		check(false, "recommendation cold start %q must be top_ranked, newest or none", c.Recommendation.ColdStart)
+	}
+
+	return errors.Join(errs...)
+}
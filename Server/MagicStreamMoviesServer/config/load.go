@@ -0,0 +1,275 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// setting ties one field of Config to its key in the config file and its
+// environment variable.
+type setting struct {
+	key string
+	env string
+	set func(c *Config, value string) error
+}
+
+var settings = []setting{
+	{"environment", "APP_ENV", lowerString(func(c *Config) *string { return &c.Environment })},
+	{"storage", "STORAGE", lowerString(func(c *Config) *string { return &c.Storage })},
+	{"server.port", "PORT", integer(func(c *Config) *int { return &c.Server.Port })},
+	{"server.request_timeout", "REQUEST_TIMEOUT", duration(func(c *Config) *time.Duration { return &c.Server.RequestTimeout })},
+	{"server.read_timeout", "READ_TIMEOUT", duration(func(c *Config) *time.Duration { return &c.Server.ReadTimeout })},
+	{"server.write_timeout", "WRITE_TIMEOUT", duration(func(c *Config) *time.Duration { return &c.Server.WriteTimeout })},
+	{"server.idle_timeout", "IDLE_TIMEOUT", duration(func(c *Config) *time.Duration { return &c.Server.IdleTimeout })},
+	{"server.drain_delay", "DRAIN_DELAY", duration(func(c *Config) *time.Duration { return &c.Server.DrainDelay })},
+	{"server.shutdown_timeout", "SHUTDOWN_TIMEOUT", duration(func(c *Config) *time.Duration { return &c.Server.ShutdownTimeout })},
+	{"server.allowed_origins", "ALLOWED_ORIGINS", list(func(c *Config) *[]string { return &c.Server.AllowedOrigins })},
+	{"server.trusted_proxies", "TRUSTED_PROXIES", list(func(c *Config) *[]string { return &c.Server.TrustedProxies })},
+	{"mongo.uri", "MONGODB_URI", str(func(c *Config) *string { return &c.Mongo.URI })},
+	{"mongo.database", "DATABASE_NAME", str(func(c *Config) *string { return &c.Mongo.Database })},
+	{"mongo.migrate", "MONGO_MIGRATE", boolean(func(c *Config) *bool { return &c.Mongo.Migrate })},
+	{"auth.signing_algorithm", "JWT_SIGNING_ALGORITHM", str(func(c *Config) *string { return &c.Auth.SigningAlgorithm })},
+	{"auth.key_dir", "JWT_KEY_DIR", str(func(c *Config) *string { return &c.Auth.KeyDir })},
+	{"auth.signing_key", "JWT_SIGNING_KEY", str(func(c *Config) *string { return &c.Auth.SigningKeyID })},
+	{"auth.retiring_keys", "JWT_RETIRING_KEYS", list(func(c *Config) *[]string { return &c.Auth.RetiringKeyIDs })},
+	{"auth.generate_keys", "JWT_GENERATE_KEYS", boolean(func(c *Config) *bool { return &c.Auth.GenerateKeys })},
+	{"auth.access_token_ttl", "ACCESS_TOKEN_TTL", duration(func(c *Config) *time.Duration { return &c.Auth.AccessTokenTTL })},
+	{"auth.refresh_token_ttl", "REFRESH_TOKEN_TTL", duration(func(c *Config) *time.Duration { return &c.Auth.RefreshTokenTTL })},
+	{"rate_limit.enabled", "RATE_LIMIT_ENABLED", boolean(func(c *Config) *bool { return &c.RateLimit.Enabled })},
+	{"rate_limit.shared", "RATE_LIMIT_SHARED", boolean(func(c *Config) *bool { return &c.RateLimit.Shared })},
+	{"rate_limit.auth", "RATE_LIMIT_AUTH", ratePolicy(func(c *Config) *RateLimitPolicy { return &c.RateLimit.Auth })},
+	{"rate_limit.public", "RATE_LIMIT_PUBLIC", ratePolicy(func(c *Config) *RateLimitPolicy { return &c.RateLimit.Public })},
+	{"rate_limit.api", "RATE_LIMIT_API", ratePolicy(func(c *Config) *RateLimitPolicy { return &c.RateLimit.API })},
+	{"login.free_attempts", "LOGIN_FREE_ATTEMPTS", integer(func(c *Config) *int { return &c.Login.FreeAttempts })},
+	{"login.ip_free_attempts", "LOGIN_IP_FREE_ATTEMPTS", integer(func(c *Config) *int { return &c.Login.IPFreeAttempts })},
+	{"login.backoff_base", "LOGIN_BACKOFF_BASE", duration(func(c *Config) *time.Duration { return &c.Login.BackoffBase })},
+	{"login.backoff_max", "LOGIN_BACKOFF_MAX", duration(func(c *Config) *time.Duration { return &c.Login.BackoffMax })},
+	{"login.failure_window", "LOGIN_FAILURE_WINDOW", duration(func(c *Config) *time.Duration { return &c.Login.FailureWindow })},
+	{"login.lockout_threshold", "LOGIN_LOCKOUT_THRESHOLD", integer(func(c *Config) *int { return &c.Login.LockoutThreshold })},
+	{"login.lockout_duration", "LOGIN_LOCKOUT_DURATION", duration(func(c *Config) *time.Duration { return &c.Login.LockoutDuration })},
+	{"account.require_verified_email", "REQUIRE_VERIFIED_EMAIL", boolean(func(c *Config) *bool { return &c.Account.RequireVerifiedEmail })},
+	{"account.verification_ttl", "EMAIL_VERIFICATION_TTL", duration(func(c *Config) *time.Duration { return &c.Account.VerificationTTL })},
+	{"account.reset_ttl", "PASSWORD_RESET_TTL", duration(func(c *Config) *time.Duration { return &c.Account.ResetTTL })},
+	{"account.verify_url", "EMAIL_VERIFY_URL", str(func(c *Config) *string { return &c.Account.VerifyURL })},
+	{"account.reset_url", "PASSWORD_RESET_URL", str(func(c *Config) *string { return &c.Account.ResetURL })},
+	{"mail.backend", "MAIL_BACKEND", lowerString(func(c *Config) *string { return &c.Mail.Backend })},
+	{"mail.from", "MAIL_FROM", str(func(c *Config) *string { return &c.Mail.From })},
+	{"mail.outbox_dir", "MAIL_OUTBOX_DIR", str(func(c *Config) *string { return &c.Mail.OutboxDir })},
+	{"mail.smtp_host", "SMTP_HOST", str(func(c *Config) *string { return &c.Mail.SMTPHost })},
+	{"mail.smtp_port", "SMTP_PORT", integer(func(c *Config) *int { return &c.Mail.SMTPPort })},
+	{"mail.smtp_username", "SMTP_USERNAME", str(func(c *Config) *string { return &c.Mail.SMTPUsername })},
+	{"mail.smtp_password", "SMTP_PASSWORD", str(func(c *Config) *string { return &c.Mail.SMTPPassword })},
+	{"classifier.backend", "REVIEW_CLASSIFIER", lowerString(func(c *Config) *string { return &c.Classifier.Backend })},
+	{"classifier.openai_api_key", "OPENAI_API_KEY", str(func(c *Config) *string { return &c.Classifier.OpenAIAPIKey })},
+	{"classifier.openai_base_url", "OPENAI_BASE_URL", str(func(c *Config) *string { return &c.Classifier.OpenAIBaseURL })},
+	{"classifier.openai_model", "OPENAI_MODEL", str(func(c *Config) *string { return &c.Classifier.OpenAIModel })},
+	{"recommendation.cold_start", "RECOMMENDATION_COLD_START", lowerString(func(c *Config) *string { return &c.Recommendation.ColdStart })},
+}
+
+func str(field func(*Config) *string) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		*field(c) = strings.TrimSpace(value)
+		return nil
+	}
+}
+
+func lowerString(field func(*Config) *string) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		*field(c) = strings.ToLower(strings.TrimSpace(value))
+		return nil
+	}
+}
+
+func integer(field func(*Config) *int) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("%q is not a whole number", value)
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+func boolean(field func(*Config) *bool) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", value)
+		}
+		*field(c) = b
+		return nil
+	}
+}
+
+func duration(field func(*Config) *time.Duration) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("%q is not a duration such as 30s or 24h", value)
+		}
+		*field(c) = d
+		return nil
+	}
+}
+
+// ratePolicy parses "limit/window", such as "10/1m".
+func ratePolicy(field func(*Config) *RateLimitPolicy) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		limit, window, ok := strings.Cut(strings.TrimSpace(value), "/")
+		n, err := strconv.Atoi(strings.TrimSpace(limit))
+		if !ok || err != nil {
+			return fmt.Errorf("%q is not a rate such as 10/1m", value)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(window))
+		if err != nil {
+			return fmt.Errorf("%q is not a rate such as 10/1m", value)
+		}
+		*field(c) = RateLimitPolicy{Limit: n, Window: d}
+		return nil
+	}
+}
+
+// list splits a comma separated value, dropping empty entries.
+func list(field func(*Config) *[]string) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		items := []string{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		*field(c) = items
+		return nil
+	}
+}
+
+// Load builds the configuration from, in increasing precedence, the
+// defaults, the config file at path, a .env file in the working directory and
+// the process environment, then validates it. When path is empty the
+// CONFIG_FILE variable names the file; without either no file is read. The
+// file is YAML or TOML depending on its extension, with the sections and
+// keys listed in settings, and must not contain unknown keys.
+func Load(path string) (*Config, error) {
+	dotenv, err := godotenv.Read(".env")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("reading .env: %w", err)
+	}
+	lookup := func(name string) string {
+		if value, ok := os.LookupEnv(name); ok {
+			return value
+		}
+		return dotenv[name]
+	}
+
+	cfg := Default()
+
+	if path == "" {
+		path = lookup("CONFIG_FILE")
+	}
+	if path != "" {
+		values, err := readFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := applyFile(&cfg, path, values); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, s := range settings {
+		value := lookup(s.env)
+		if value == "" {
+			continue
+		}
+		if err := s.set(&cfg, value); err != nil {
+			return nil, fmt.Errorf("%s: %w", s.env, err)
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return &cfg, nil
+}
+
+// readFile decodes a YAML or TOML file into its dotted keys, such as
+// "server.port", and their values as text.
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+
+	var raw map[string]interface{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return nil, fmt.Errorf("config file %s: unsupported extension %q, expected .yaml, .yml or .toml", path, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+
+	values := map[string]string{}
+	flatten("", raw, values)
+	return values, nil
+}
+
+func flatten(prefix string, raw map[string]interface{}, values map[string]string) {
+	for key, value := range raw {
+		key = prefix + key
+		switch value := value.(type) {
+		case map[string]interface{}:
+			flatten(key+".", value, values)
+		case []interface{}:
+			items := make([]string, len(value))
+			for i, item := range value {
+				items[i] = fmt.Sprint(item)
+			}
+			values[key] = strings.Join(items, ",")
+		case nil:
+			values[key] = ""
+		default:
+			values[key] = fmt.Sprint(value)
+		}
+	}
+}
+
+func applyFile(cfg *Config, path string, values map[string]string) error {
+	known := map[string]setting{}
+	for _, s := range settings {
+		known[s.key] = s
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s, ok := known[key]
+		if !ok {
+			return fmt.Errorf("config file %s: unknown key %q", path, key)
+		}
+		if err := s.set(cfg, values[key]); err != nil {
+			return fmt.Errorf("config file %s: %s: %w", path, key, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,270 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/config"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/mailer"
+	model "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/repository"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/utils"
+)
+
+// mailTimeout bounds sending one account email in the background.
+const mailTimeout = 30 * time.Second
+
+// createUserToken stores a new single-use token mailed to email for user and
+// returns it. The user's earlier tokens for the same purpose stop working, so
+// only the newest email is valid.
+func createUserToken(ctx context.Context, userTokens repository.UserTokenRepository, user model.User, email, purpose string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	if err := userTokens.InvalidateUser(ctx, user.UserID, purpose, now); err != nil {
+		return "", err
+	}
+
+	token := utils.NewTokenID()
+	err := userTokens.Create(ctx, &model.UserToken{
+		UserID:    user.UserID,
+		Purpose:   purpose,
+		TokenHash: utils.HashToken(token),
+		Email:     email,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// tokenLink appends the token to a configured link as ?token=.
+func tokenLink(link, token string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		// config.Validate has checked the link is an http(s) URL
+		return link + "?token=" + url.QueryEscape(token)
+	}
+	query := u.Query()
+	query.Set("token", token)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// readableDuration writes a link lifetime the way an email reader expects,
+// such as "48 hours" rather than "48h0m0s".
+func readableDuration(d time.Duration) string {
+	switch {
+	case d == time.Hour:
+		return "1 hour"
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%d hours", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%d minutes", d/time.Minute)
+	}
+	return d.String()
+}
+
+// sendVerification mails a link to email that verifies user controls it. It
+// is either the user's address or the pending one they want to change to.
+func sendVerification(ctx context.Context, userTokens repository.UserTokenRepository, mail mailer.Mailer, account config.Account, user model.User, email string) error {
+	token, err := createUserToken(ctx, userTokens, user, email, model.PurposeVerifyEmail, account.VerificationTTL)
+	if err != nil {
+		return err
+	}
+	return mail.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: "Verify your MagicStream email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening this link:\n\n%s\n\nThe link expires in %s. If you did not sign up for MagicStream you can ignore this email.\n",
+			user.FirstName, tokenLink(account.VerifyURL, token), readableDuration(account.VerificationTTL)),
+	})
+}
+
+// sendPasswordReset mails user a link to choose a new password.
+func sendPasswordReset(ctx context.Context, userTokens repository.UserTokenRepository, mail mailer.Mailer, account config.Account, user model.User) error {
+	token, err := createUserToken(ctx, userTokens, user, user.Email, model.PurposeResetPassword, account.ResetTTL)
+	if err != nil {
+		return err
+	}
+	return mail.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your MagicStream password",
+		Body: fmt.Sprintf("Hi %s,\n\nSomeone asked to reset the password of your MagicStream account. To choose a new password open this link:\n\n%s\n\nThe link expires in %s. If it was not you, ignore this email and your password stays the same.\n",
+			user.FirstName, tokenLink(account.ResetURL, token), readableDuration(account.ResetTTL)),
+	})
+}
+
+// inBackground runs an account email job after the response has been sent,
+// so the time a request takes does not reveal whether the address has an
+// account. Failures can only be logged.
+func inBackground(ctx context.Context, what string, job func(ctx context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), mailTimeout)
+	go func() {
+		defer cancel()
+		if err := job(ctx); err != nil {
+			log.Printf("Warning: unable to send %s: %v", what, err)
+		}
+	}()
+}
+
+//--------------------------------------------------------------------------------------------
+// Verify an email address with the token from the verification email. For a
+// pending address this is when the user's address changes.
+func VerifyEmail(users repository.UserRepository, userTokens repository.UserTokenRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		token := c.Query("token")
+		if token == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Verification token is required"})
+			return
+		}
+
+		now := time.Now()
+		userToken, err := userTokens.Consume(ctx, model.PurposeVerifyEmail, utils.HashToken(token), now)
+		if err == nil {
+			// Fails when the user has changed their address since
+			err = users.MarkEmailVerified(ctx, userToken.UserID, userToken.Email, now)
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired verification link"})
+			return
+		}
+		if errors.Is(err, repository.ErrDuplicate) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Email address is already in use"})
+			return
+		}
+		if err != nil {
+			log.Println("Error: failed to verify email address:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email address"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Email address verified"})
+	}
+}
+
+//--------------------------------------------------------------------------------------------
+// Send a new verification email. The answer is the same whether or not the
+// address has an unverified account, so it cannot be used to find accounts.
+func ResendVerification(users repository.UserRepository, userTokens repository.UserTokenRepository, mail mailer.Mailer, account config.Account) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request model.EmailRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input data"})
+			return
+		}
+		validate := validator.New()
+		if err := validate.Struct(request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+			return
+		}
+
+		inBackground(c.Request.Context(), "verification email", func(ctx context.Context) error {
+			user, err := users.GetByEmail(ctx, request.Email)
+			if errors.Is(err, repository.ErrNotFound) || (err == nil && (user.EmailVerified || user.AccountStatus() == model.UserDeleted)) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			return sendVerification(ctx, userTokens, mail, account, user, user.Email)
+		})
+
+		c.JSON(http.StatusAccepted, gin.H{"message": "If the address has an unverified account, a verification email is on its way"})
+	}
+}
+
+//--------------------------------------------------------------------------------------------
+// Email a password reset link. The answer is the same whether or not the
+// address has an account, so it cannot be used to find accounts.
+func RequestPasswordReset(users repository.UserRepository, userTokens repository.UserTokenRepository, mail mailer.Mailer, account config.Account) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request model.EmailRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input data"})
+			return
+		}
+		validate := validator.New()
+		if err := validate.Struct(request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+			return
+		}
+
+		inBackground(c.Request.Context(), "password reset email", func(ctx context.Context) error {
+			user, err := users.GetByEmail(ctx, request.Email)
+			if errors.Is(err, repository.ErrNotFound) || (err == nil && user.AccountStatus() == model.UserDeleted) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			return sendPasswordReset(ctx, userTokens, mail, account, user)
+		})
+
+		c.JSON(http.StatusAccepted, gin.H{"message": "If the address has an account, a password reset email is on its way"})
+	}
+}
+
+//--------------------------------------------------------------------------------------------
+// Set a new password with the token from a password reset email. Every
+// device is signed out. Following the link proves the user reads mail at
+// the address, so it also counts as verifying it.
+func ResetPassword(users repository.UserRepository, userTokens repository.UserTokenRepository, sessions repository.SessionRepository, revocations repository.RevocationRepository, tokens *utils.Tokens) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		var request model.PasswordResetRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input data"})
+			return
+		}
+		validate := validator.New()
+		if err := validate.Struct(request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+			return
+		}
+
+		hashedPassword, err := HashPassword(request.Password)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+			return
+		}
+
+		now := time.Now()
+		userToken, err := userTokens.Consume(ctx, model.PurposeResetPassword, utils.HashToken(request.Token), now)
+		if err == nil {
+			err = users.SetPassword(ctx, userToken.UserID, hashedPassword, now)
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired password reset link"})
+			return
+		}
+		if err != nil {
+			log.Println("Error: failed to reset password:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+			return
+		}
+
+		if err := revokeUser(ctx, sessions, revocations, tokens, userToken.UserID, model.RevokedPasswordChanged); err != nil {
+			log.Println("Error: password changed but failed to sign out other devices:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Password changed but failed to sign out other devices"})
+			return
+		}
+		err = users.MarkEmailVerified(ctx, userToken.UserID, userToken.Email, now)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			log.Println("Warning: unable to mark email verified after password reset:", err)
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Password has been reset; please log in again"})
+	}
+}
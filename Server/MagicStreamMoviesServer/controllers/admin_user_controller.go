@@ -0,0 +1,308 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/loginguard"
+	model "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/repository"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/utils"
+)
+
+const (
+	adminUsersCursorSort = "-created_at"
+	auditCursorSort      = "-at"
+)
+
+// errLastAdmin is returned by checkLastAdmin when a change would leave no
+// active admin to undo it.
+var errLastAdmin = errors.New("cannot remove the last active admin")
+
+// checkLastAdmin returns errLastAdmin if user is the only active admin. Call
+// it before demoting, disabling or deleting user. Two admins removing each
+// other at the same moment can still both succeed.
+func checkLastAdmin(ctx context.Context, users repository.UserRepository, user model.User) error {
+	if user.Role != "ADMIN" || user.AccountStatus() != model.UserActive {
+		return nil
+	}
+	count, err := users.CountActiveAdmins(ctx)
+	if err != nil {
+		return err
+	}
+	if count <= 1 {
+		return errLastAdmin
+	}
+	return nil
+}
+
+// recordAudit writes an admin action by the current user to the audit
+// trail. The action has already happened, so a failure is only logged.
+func recordAudit(ctx context.Context, c *gin.Context, audit repository.AuditRepository, action, targetID string, details map[string]string) {
+	entry := model.AuditEntry{
+		ActorID:  c.GetString("userId"),
+		Action:   action,
+		TargetID: targetID,
+		Details:  details,
+		At:       time.Now(),
+	}
+	if err := audit.Record(ctx, entry); err != nil {
+		log.Printf("Error: unable to audit %s of user %s by %s: %v", action, targetID, entry.ActorID, err)
+	}
+}
+
+//--------------------------------------------------------------------------------------------
+// List users newest first. q searches the email and names; role and status
+// filter. Deleted users are only listed with status=deleted.
+func ListUsers(users repository.UserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		filter := repository.UserFilter{Query: c.Query("q"), Role: c.Query("role"), Status: c.Query("status")}
+		switch filter.Role {
+		case "", "ADMIN", "USER":
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": "role must be ADMIN or USER"})
+			return
+		}
+		switch filter.Status {
+		case "", model.UserActive, model.UserDisabled, model.UserDeleted:
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": "status must be active, disabled or deleted"})
+			return
+		}
+
+		limit, err := parseLimit(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+			return
+		}
+		offset, err := parseOffsetCursor(c, adminUsersCursorSort)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+			return
+		}
+
+		result, err := users.Page(ctx, filter, offset, limit)
+		if err != nil {
+			log.Println("Error: failed to fetch users:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+			return
+		}
+
+		page := model.Page[model.ProfileResponse]{
+			Items:      make([]model.ProfileResponse, len(result.Items)),
+			Total:      result.Total,
+			NextCursor: nextOffsetCursor(adminUsersCursorSort, offset, limit, result.Total),
+		}
+		for i, user := range result.Items {
+			page.Items[i] = profileResponse(user)
+		}
+		c.JSON(http.StatusOK, page)
+	}
+}
+
+//--------------------------------------------------------------------------------------------
+// Change a user's role or status. Either change signs the user out
+// everywhere, so their tokens cannot carry the old role or outlive the
+// account being disabled.
+func UpdateUser(users repository.UserRepository, sessions repository.SessionRepository, revocations repository.RevocationRepository, tokens *utils.Tokens, audit repository.AuditRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		var update model.AdminUserUpdate
+		if err := c.ShouldBindJSON(&update); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input data"})
+			return
+		}
+		validate := validator.New()
+		if err := validate.Struct(update); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+			return
+		}
+
+		user, err := users.GetByID(ctx, c.Param("user_id"))
+		if err == nil && user.AccountStatus() == model.UserDeleted {
+			err = repository.ErrNotFound
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		if err != nil {
+			log.Println("Error: failed to fetch user:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+			return
+		}
+
+		// Drop the fields that would not change anything
+		if update.Role != nil && *update.Role == user.Role {
+			update.Role = nil
+		}
+		if update.Status != nil && *update.Status == user.AccountStatus() {
+			update.Status = nil
+		}
+		if update.Role == nil && update.Status == nil {
+			c.JSON(http.StatusOK, profileResponse(user))
+			return
+		}
+
+		if err := checkLastAdmin(ctx, users, user); err != nil {
+			if errors.Is(err, errLastAdmin) {
+				c.JSON(http.StatusConflict, gin.H{"error": "Cannot demote or disable the last active admin"})
+				return
+			}
+			log.Println("Error: failed to count admins:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count admins"})
+			return
+		}
+
+		updated, err := users.Update(ctx, user.UserID, repository.UserChanges{Role: update.Role, Status: update.Status}, time.Now())
+		if err != nil {
+			log.Println("Error: failed to update user:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+			return
+		}
+
+		reason := model.RevokedRoleChanged
+		if update.Role != nil {
+			recordAudit(ctx, c, audit, model.AuditUserRoleChanged, user.UserID, map[string]string{"from": user.Role, "to": updated.Role})
+		}
+		if update.Status != nil {
+			recordAudit(ctx, c, audit, model.AuditUserStatusChanged, user.UserID, map[string]string{"from": user.AccountStatus(), "to": updated.AccountStatus()})
+			if updated.AccountStatus() == model.UserDisabled {
+				reason = model.RevokedAccountDisabled
+			}
+		}
+
+		if err := revokeUser(ctx, sessions, revocations, tokens, user.UserID, reason); err != nil {
+			log.Println("Error: user updated but failed to sign them out:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "User updated but failed to sign them out"})
+			return
+		}
+
+		c.JSON(http.StatusOK, profileResponse(updated))
+	}
+}
+
+//--------------------------------------------------------------------------------------------
+// Soft delete a user and sign them out everywhere. With ?anonymise=true their
+// name, email and password are replaced, which cannot be undone.
+func DeleteUser(users repository.UserRepository, sessions repository.SessionRepository, revocations repository.RevocationRepository, tokens *utils.Tokens, audit repository.AuditRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		anonymise, err := strconv.ParseBool(c.DefaultQuery("anonymise", "false"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": "anonymise must be true or false"})
+			return
+		}
+
+		user, err := users.GetByID(ctx, c.Param("user_id"))
+		if err == nil && user.AccountStatus() == model.UserDeleted {
+			err = repository.ErrNotFound
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		if err != nil {
+			log.Println("Error: failed to fetch user:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+			return
+		}
+
+		if err := checkLastAdmin(ctx, users, user); err != nil {
+			if errors.Is(err, errLastAdmin) {
+				c.JSON(http.StatusConflict, gin.H{"error": "Cannot delete the last active admin"})
+				return
+			}
+			log.Println("Error: failed to count admins:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count admins"})
+			return
+		}
+
+		_, err = users.Delete(ctx, user.UserID, anonymise, time.Now())
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		if err != nil {
+			log.Println("Error: failed to delete user:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
+			return
+		}
+		recordAudit(ctx, c, audit, model.AuditUserDeleted, user.UserID, map[string]string{"anonymised": strconv.FormatBool(anonymise)})
+
+		if err := revokeUser(ctx, sessions, revocations, tokens, user.UserID, model.RevokedAccountDeleted); err != nil {
+			log.Println("Error: user deleted but failed to sign them out:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "User deleted but failed to sign them out"})
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+//--------------------------------------------------------------------------------------------
+// Unlock an account locked by failed logins and forget its failures
+func UnlockUser(guard *loginguard.Guard, audit repository.AuditRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		user, err := guard.Unlock(ctx, c.Param("user_id"), time.Now())
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		if err != nil {
+			log.Println("Error: failed to unlock user:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock user"})
+			return
+		}
+		recordAudit(ctx, c, audit, model.AuditUserUnlocked, user.UserID, nil)
+
+		c.JSON(http.StatusOK, gin.H{"message": "User unlocked", "user_id": user.UserID})
+	}
+}
+
+//--------------------------------------------------------------------------------------------
+// List the audit trail newest first, optionally only the actions by
+// actor_id or on user_id
+func GetAuditLog(audit repository.AuditRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		limit, err := parseLimit(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+			return
+		}
+		offset, err := parseOffsetCursor(c, auditCursorSort)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+			return
+		}
+
+		filter := repository.AuditFilter{ActorID: c.Query("actor_id"), TargetID: c.Query("user_id")}
+		result, err := audit.Page(ctx, filter, offset, limit)
+		if err != nil {
+			log.Println("Error: failed to fetch audit trail:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audit trail"})
+			return
+		}
+
+		c.JSON(http.StatusOK, offsetPage(result, auditCursorSort, offset, limit))
+	}
+}
@@ -0,0 +1,315 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	model "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/repository"
+)
+
+// errInvalidGenre is returned by resolveGenres and checkGenres for a genre
+// missing from the genre list or named differently there.
+var errInvalidGenre = errors.New("invalid genre")
+
+// genreIndex fetches the genre list keyed by ID.
+func genreIndex(ctx context.Context, genres repository.GenreRepository) (map[int]model.Genre, error) {
+	all, err := genres.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]model.Genre, len(all))
+	for _, genre := range all {
+		byID[genre.GenreID] = genre
+	}
+	return byID, nil
+}
+
+// resolveGenres looks the IDs up in the genre list, so the names stored on
+// the user are the catalogue's and not whatever the client sent.
+func resolveGenres(ctx context.Context, genres repository.GenreRepository, ids []int) ([]model.Genre, error) {
+	byID, err := genreIndex(ctx, genres)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := make([]model.Genre, 0, len(ids))
+	for _, id := range ids {
+		genre, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown genre %d", errInvalidGenre, id)
+		}
+		resolved = append(resolved, genre)
+	}
+	return resolved, nil
+}
+
+// checkGenres makes sure every genre embedded in a movie or user matches the
+// genre list by ID and name, so the copies start out in step with it.
+func checkGenres(byID map[int]model.Genre, embedded []model.Genre) error {
+	for _, genre := range embedded {
+		canonical, ok := byID[genre.GenreID]
+		if !ok {
+			return fmt.Errorf("%w: unknown genre %d", errInvalidGenre, genre.GenreID)
+		}
+		if genre.GenreName != canonical.GenreName {
+			return fmt.Errorf("%w: genre %d is named %q, not %q", errInvalidGenre, genre.GenreID, canonical.GenreName, genre.GenreName)
+		}
+	}
+	return nil
+}
+
+// validateGenres is checkGenres against the current genre list.
+func validateGenres(ctx context.Context, genres repository.GenreRepository, embedded []model.Genre) error {
+	byID, err := genreIndex(ctx, genres)
+	if err != nil {
+		return err
+	}
+	return checkGenres(byID, embedded)
+}
+
+// genreError answers a failed checkGenres or validateGenres.
+func genreError(c *gin.Context, err error) {
+	if errors.Is(err, errInvalidGenre) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid genre", "details": err.Error()})
+		return
+	}
+	log.Println("Error: failed to fetch genres:", err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch genres"})
+}
+
+// genreNameTaken reports whether another genre than genreID is already
+// called name, ignoring case.
+func genreNameTaken(ctx context.Context, genres repository.GenreRepository, genreID int, name string) (bool, error) {
+	all, err := genres.List(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, genre := range all {
+		if genre.GenreID != genreID && strings.EqualFold(genre.GenreName, name) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// parseGenreID reads a genre ID from the URL or query.
+func parseGenreID(value string) (int, error) {
+	id, err := strconv.Atoi(value)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("genre ID must be a positive integer, got %q", value)
+	}
+	return id, nil
+}
+
+//--------------------------------------------------------------------------------------------
+// Add a genre. Without a genre_id it gets the next unused ID.
+func CreateGenre(genres repository.GenreRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		var request model.GenreCreate
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input data"})
+			return
+		}
+		request.GenreName = strings.TrimSpace(request.GenreName)
+		validate := validator.New()
+		if err := validate.Struct(request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+			return
+		}
+
+		all, err := genres.List(ctx)
+		if err != nil {
+			log.Println("Error: failed to fetch genres:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch genres"})
+			return
+		}
+		genre := model.Genre{GenreID: request.GenreID, GenreName: request.GenreName}
+		for _, existing := range all {
+			if strings.EqualFold(existing.GenreName, genre.GenreName) {
+				c.JSON(http.StatusConflict, gin.H{"error": "A genre with this name already exists", "genre": existing})
+				return
+			}
+			if request.GenreID == 0 && existing.GenreID >= genre.GenreID {
+				genre.GenreID = existing.GenreID + 1
+			}
+		}
+		if genre.GenreID == 0 {
+			genre.GenreID = 1
+		}
+
+		err = genres.Insert(ctx, genre)
+		if errors.Is(err, repository.ErrDuplicate) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Genre ID is already in use"})
+			return
+		}
+		if err != nil {
+			log.Println("Error: failed to add genre:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add genre"})
+			return
+		}
+
+		c.JSON(http.StatusCreated, genre)
+	}
+}
+
+//--------------------------------------------------------------------------------------------
+// Rename a genre and the copies of it in movies and favourite genres. If the
+// copies cannot all be updated, repeating the request finishes the job.
+func RenameGenre(genres repository.GenreRepository, movies repository.MovieRepository, users repository.UserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		genreID, err := parseGenreID(c.Param("genre_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid genre ID", "details": err.Error()})
+			return
+		}
+
+		var request model.GenreRename
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input data"})
+			return
+		}
+		request.GenreName = strings.TrimSpace(request.GenreName)
+		validate := validator.New()
+		if err := validate.Struct(request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+			return
+		}
+
+		taken, err := genreNameTaken(ctx, genres, genreID, request.GenreName)
+		if err != nil {
+			log.Println("Error: failed to fetch genres:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch genres"})
+			return
+		}
+		if taken {
+			c.JSON(http.StatusConflict, gin.H{"error": "A genre with this name already exists"})
+			return
+		}
+
+		genre, err := genres.Rename(ctx, genreID, request.GenreName)
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Genre not found"})
+			return
+		}
+		if err != nil {
+			log.Println("Error: failed to rename genre:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rename genre"})
+			return
+		}
+
+		moviesUpdated, err := movies.ReplaceGenre(ctx, genreID, &genre)
+		if err != nil {
+			log.Println("Error: genre renamed but failed to update movies; repeat the request:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Genre renamed but failed to update movies; repeat the request"})
+			return
+		}
+		usersUpdated, err := users.ReplaceGenre(ctx, genreID, &genre)
+		if err != nil {
+			log.Println("Error: genre renamed but failed to update favourite genres; repeat the request:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Genre renamed but failed to update favourite genres; repeat the request"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"genre": genre, "movies_updated": moviesUpdated, "users_updated": usersUpdated})
+	}
+}
+
+//--------------------------------------------------------------------------------------------
+// Delete a genre. While movies list it this is refused unless reassign_to
+// names the genre that replaces it in those movies and in favourite genres.
+// Otherwise it is just dropped from favourite genres.
+func DeleteGenre(genres repository.GenreRepository, movies repository.MovieRepository, users repository.UserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		genreID, err := parseGenreID(c.Param("genre_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid genre ID", "details": err.Error()})
+			return
+		}
+
+		var replacement *model.Genre
+		if value := c.Query("reassign_to"); value != "" {
+			targetID, err := parseGenreID(value)
+			if err == nil && targetID == genreID {
+				err = errors.New("a genre cannot be reassigned to itself")
+			}
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+				return
+			}
+			target, err := genres.Get(ctx, targetID)
+			if errors.Is(err, repository.ErrNotFound) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": fmt.Sprintf("genre %d does not exist", targetID)})
+				return
+			}
+			if err != nil {
+				log.Println("Error: failed to fetch genre:", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch genre"})
+				return
+			}
+			replacement = &target
+		}
+
+		if _, err := genres.Get(ctx, genreID); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Genre not found"})
+				return
+			}
+			log.Println("Error: failed to fetch genre:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch genre"})
+			return
+		}
+
+		if replacement == nil {
+			count, err := movies.CountWithGenre(ctx, genreID)
+			if err != nil {
+				log.Println("Error: failed to count movies:", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count movies"})
+				return
+			}
+			if count > 0 {
+				c.JSON(http.StatusConflict, gin.H{"error": "Genre is used by movies; pass reassign_to to move them to another genre", "movies": count})
+				return
+			}
+		}
+
+		// The copies go first, so a failure leaves the genre in place to retry
+		moviesUpdated, err := movies.ReplaceGenre(ctx, genreID, replacement)
+		if err != nil {
+			log.Println("Error: failed to update movies:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update movies"})
+			return
+		}
+		usersUpdated, err := users.ReplaceGenre(ctx, genreID, replacement)
+		if err != nil {
+			log.Println("Error: failed to update favourite genres:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update favourite genres"})
+			return
+		}
+
+		err = genres.Delete(ctx, genreID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			log.Println("Error: failed to delete genre:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete genre"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Genre deleted", "movies_updated": moviesUpdated, "users_updated": usersUpdated})
+	}
+}
@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/health"
+)
+
+// readinessCheckTimeout bounds each dependency check, so a hung database
+// fails the probe instead of hanging it.
+const readinessCheckTimeout = 2 * time.Second
+
+//--------------------------------------------------------------------------------------------
+// Liveness probe: the process is up and serving requests
+func Healthz() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	}
+}
+
+//--------------------------------------------------------------------------------------------
+// Readiness probe: every dependency answers and the server is not draining.
+// Answers 503 with the per-dependency status otherwise.
+func Readyz(probe *health.Probe) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report := probe.Check(c.Request.Context(), readinessCheckTimeout)
+
+		status := http.StatusOK
+		if !report.Ready {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, report)
+	}
+}
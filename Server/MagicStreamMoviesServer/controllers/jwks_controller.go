@@ -0,0 +1,19 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/jwks"
+)
+
+//--------------------------------------------------------------------------------------------
+// Publish the public keys tokens are signed with, so other services can
+// verify them. Retiring keys are included until they are removed.
+func GetJWKS(keys *jwks.KeySet) gin.HandlerFunc {
+	document := keys.Document()
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, document)
+	}
+}
@@ -0,0 +1,323 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	models "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/repository"
+)
+
+// maxImportItems caps the size of a single POST /movies/import request.
+const maxImportItems = 1000
+
+// ImportResult reports what happened to one item of a bulk import.
+type ImportResult struct {
+	Index  int    `json:"index"`
+	ImdbID string `json:"imdb_id,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// decodeMovieStrict decodes a movie document, rejecting unknown fields so a
+// typo in a field name is reported instead of silently dropped.
+func decodeMovieStrict(data []byte) (models.Movie, error) {
+	var movie models.Movie
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&movie)
+	return movie, err
+}
+
+// applyMergePatch applies an RFC 7386 JSON merge patch to target: objects are
+// merged recursively, null removes a member and any other value replaces it.
+func applyMergePatch(target interface{}, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = applyMergePatch(targetObject[key], value)
+	}
+	return targetObject
+}
+
+//--------------------------------------------------------------------------------------------
+// Replace every editable field of a movie
+func UpdateMovie(movies repository.MovieRepository, genres repository.GenreRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		movieID := c.Param("imdb_id")
+		if movieID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Movie ID is required"})
+			return
+		}
+
+		var movie models.Movie
+		if err := c.ShouldBindJSON(&movie); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+			return
+		}
+		if movie.ImdbID == "" {
+			movie.ImdbID = movieID
+		}
+		if movie.ImdbID != movieID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "imdb_id in the body does not match the URL"})
+			return
+		}
+		if err := validate.Struct(movie); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+			return
+		}
+		if err := validateGenres(ctx, genres, movie.Genre); err != nil {
+			genreError(c, err)
+			return
+		}
+
+		updated, err := movies.Replace(ctx, movieID, movie)
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update movie"})
+			return
+		}
+		c.JSON(http.StatusOK, updated)
+	}
+}
+
+//--------------------------------------------------------------------------------------------
+// Partially update a movie with a JSON merge patch (RFC 7386). The patched
+// movie must still pass the models.Movie validation.
+func PatchMovie(movies repository.MovieRepository, genres repository.GenreRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		movieID := c.Param("imdb_id")
+		if movieID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Movie ID is required"})
+			return
+		}
+
+		body, err := c.GetRawData()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+			return
+		}
+		var patch interface{}
+		if err := json.Unmarshal(body, &patch); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+			return
+		}
+		patchObject, ok := patch.(map[string]interface{})
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Merge patch must be a JSON object"})
+			return
+		}
+		editable := repository.EditableMovieFields(models.Movie{})
+		for field := range patchObject {
+			if _, ok := editable[field]; !ok {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patch", "details": fmt.Sprintf("field %q cannot be patched", field)})
+				return
+			}
+		}
+
+		existing, err := movies.Get(ctx, movieID)
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch movie"})
+			return
+		}
+
+		current, _ := json.Marshal(repository.EditableMovieFields(existing))
+		var document interface{}
+		_ = json.Unmarshal(current, &document)
+		patched, _ := json.Marshal(applyMergePatch(document, patch))
+
+		movie, err := decodeMovieStrict(patched)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patch", "details": err.Error()})
+			return
+		}
+		if movie.ImdbID != movieID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "imdb_id cannot be changed"})
+			return
+		}
+		if err := validate.Struct(movie); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+			return
+		}
+		if err := validateGenres(ctx, genres, movie.Genre); err != nil {
+			genreError(c, err)
+			return
+		}
+
+		updated, err := movies.Replace(ctx, movieID, movie)
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update movie"})
+			return
+		}
+		c.JSON(http.StatusOK, updated)
+	}
+}
+
+//--------------------------------------------------------------------------------------------
+// Soft delete a movie. It disappears from every listing until restored.
+func DeleteMovie(movies repository.MovieRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		movieID := c.Param("imdb_id")
+		if movieID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Movie ID is required"})
+			return
+		}
+
+		err := movies.SoftDelete(ctx, movieID, time.Now())
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete movie"})
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+//--------------------------------------------------------------------------------------------
+// Restore a soft deleted movie
+func RestoreMovie(movies repository.MovieRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		movieID := c.Param("imdb_id")
+		if movieID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Movie ID is required"})
+			return
+		}
+
+		restored, err := movies.Restore(ctx, movieID)
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Deleted movie not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore movie"})
+			return
+		}
+
+		c.JSON(http.StatusOK, restored)
+	}
+}
+
+//--------------------------------------------------------------------------------------------
+// Bulk import movies in the MagicStreamSeedData/movies.json format. Each item
+// is validated, its genres checked against the genre list, and upserted on
+// imdb_id independently, and the response reports the outcome of every item.
+// Soft deleted movies are reported as deleted and left as they are.
+func ImportMovies(movies repository.MovieRepository, genres repository.GenreRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		var items []json.RawMessage
+		if err := c.ShouldBindJSON(&items); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Request body must be a JSON array of movies"})
+			return
+		}
+		if len(items) > maxImportItems {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("At most %d movies can be imported at once", maxImportItems)})
+			return
+		}
+
+		genresByID, err := genreIndex(ctx, genres)
+		if err != nil {
+			log.Println("Error: failed to fetch genres:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch genres"})
+			return
+		}
+
+		results := make([]ImportResult, len(items))
+		counts := map[string]int{"created": 0, "updated": 0, "deleted": 0, "failed": 0}
+		for i, item := range items {
+			results[i] = importMovie(ctx, movies, genresByID, i, item)
+			counts[results[i].Status]++
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"created": counts["created"],
+			"updated": counts["updated"],
+			"deleted": counts["deleted"],
+			"failed":  counts["failed"],
+			"results": results,
+		})
+	}
+}
+
+func importMovie(ctx context.Context, movies repository.MovieRepository, genresByID map[int]models.Genre, index int, item json.RawMessage) ImportResult {
+	result := ImportResult{Index: index, Status: "failed"}
+
+	movie, err := decodeMovieStrict(item)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.ImdbID = movie.ImdbID
+
+	if err := validate.Struct(movie); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if err := checkGenres(genresByID, movie.Genre); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	created, err := movies.Upsert(ctx, movie)
+	if errors.Is(err, repository.ErrDeleted) {
+		result.Status = "deleted"
+		result.Error = "movie is deleted; restore it before importing it again"
+		return result
+	}
+	if err != nil {
+		result.Error = "failed to save movie"
+		return result
+	}
+
+	result.Status = "updated"
+	if created {
+		result.Status = "created"
+	}
+	return result
+}
@@ -2,50 +2,48 @@ package controllers
 
 import (
 	"context"
+	"errors"
+	"log"
 	"net/http"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
-	database "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/database"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/ai"
 	models "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/recommendation"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/repository"
 )
 
 var validate = validator.New()
 
-// Create client and collection
-var client *mongo.Client = database.Connect()
-var movieCollection *mongo.Collection = database.OpenCollection("movies", client)
-
-func GetMovies() gin.HandlerFunc {
+// GetMovies lists the catalogue one page at a time. It accepts genre_id,
+// min_ranking, max_ranking and title_prefix filters, a sort of title, ranking
+// or created (prefix with '-' for descending), a limit and the next_cursor
+// returned by the previous page.
+func GetMovies(movies repository.MovieRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		ctx, cancel := requestContext(c)
 		defer cancel()
 
-		var movies []models.Movie
-
-		cursor, err := movieCollection.Find(ctx, bson.M{})
-
+		query, err := parseMovieListQuery(c)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching movies"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
 			return
 		}
-		defer cursor.Close(ctx)
 
-		if err = cursor.All(ctx, &movies); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode movies"})
+		page, err := findMoviePage(ctx, movies, query)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching movies"})
 			return
 		}
-		c.JSON(http.StatusOK, movies)
+		c.JSON(http.StatusOK, page)
 	}
 }
 
 //--------------------------------------------------------------------------------------------
-func GetMovieByID() gin.HandlerFunc {
+func GetMovieByID(movies repository.MovieRepository) gin.HandlerFunc {
 	return func(c *gin.Context){
-		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		ctx, cancel := requestContext(c)
 		defer cancel()
 
 		movieID := c.Param("imdb_id")
@@ -55,11 +53,7 @@ func GetMovieByID() gin.HandlerFunc {
 			return
 		}
 
-		var movieCollection *mongo.Collection = database.OpenCollection("movies", client)
-
-		var movie models.Movie
-
-		err := movieCollection.FindOne(ctx, bson.D{{Key: "imdb_id", Value: movieID}}).Decode(&movie)
+		movie, err := movies.Get(ctx, movieID)
 
 		if err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
@@ -71,9 +65,9 @@ func GetMovieByID() gin.HandlerFunc {
 }
 //--------------------------------------------------------------------------------------------
 // post request to add movie
-func AddMovie(client *mongo.Client) gin.HandlerFunc {
+func AddMovie(movies repository.MovieRepository, genres repository.GenreRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(c, 100*time.Second)
+		ctx, cancel := requestContext(c)
 		defer cancel()
 
 		var movie models.Movie
@@ -86,37 +80,71 @@ func AddMovie(client *mongo.Client) gin.HandlerFunc {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
 			return
 		}
-		var movieCollection *mongo.Collection = database.OpenCollection("movies", client)
-
-		result, err := movieCollection.InsertOne(ctx, movie)
-
+		if err := validateGenres(ctx, genres, movie.Genre); err != nil {
+			genreError(c, err)
+			return
+		}
+		// Audience aggregates are derived from ratings, never client supplied
+		movie.AudienceScore = 0
+		movie.RatingCount = 0
+		movie.DeletedAt = nil
+
+		// Deleted movies still own their imdb_id until they are restored
+		err := movies.Insert(ctx, &movie)
+		if errors.Is(err, repository.ErrDuplicate) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Movie already exists"})
+			return
+		}
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add movie"})
 			return
 		}
-		c.JSON(http.StatusCreated, result)
+		c.JSON(http.StatusCreated, gin.H{"InsertedID": movie.ID})
+	}
+}
+
+//--------------------------------------------------------------------------------------------
+// Fetch recommendations for users the scorer cannot personalise for, skipping
+// the excluded imdb_ids
+func coldStartRecommendations(ctx context.Context, movies repository.MovieRepository, strategy recommendation.ColdStartStrategy, exclude []string, limit int) ([]models.RecommendedMovie, error) {
+	filter, sort, ok := strategy.Query()
+	if !ok {
+		return []models.RecommendedMovie{}, nil
+	}
+	filter.ExcludeImdbIDs = exclude
+
+	found, err := movies.Find(ctx, filter, sort, limit)
+	if err != nil {
+		return nil, err
 	}
+	return strategy.Wrap(found), nil
 }
 
 //--------------------------------------------------------------------------------------------
-// Get recommended movies based on user's favorite genres
-func GetRecommendedMovies(client *mongo.Client) gin.HandlerFunc {
+// Get recommended movies based on user's favorite genres. Candidates sharing a
+// favourite genre are scored by the recommendation scorer and returned best
+// first with the score and the reasons behind it. Users without favourite
+// genres, or whose genres match nothing, get the cold-start list instead.
+func GetRecommendedMovies(users repository.UserRepository, movies repository.MovieRepository, ratings repository.RatingRepository, history repository.WatchHistoryRepository, coldStart recommendation.ColdStartStrategy) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		ctx, cancel := requestContext(c)
 		defer cancel()
 
 		// Get user ID from middleware
-		userID, exists := c.Get("userId")
-		if !exists {
+		userID := c.GetString("userId")
+		if userID == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
 			return
 		}
 
-		// Get user collection to fetch user's favorite genres
-		var userCollection *mongo.Collection = database.OpenCollection("users", client)
-		var user models.User
+		limit, err := parseLimit(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+			return
+		}
 
-		err := userCollection.FindOne(ctx, bson.D{{Key: "user_id", Value: userID}}).Decode(&user)
+		// Fetch the user's favorite genres
+		user, err := users.GetByID(ctx, userID)
 		if err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 			return
@@ -128,62 +156,41 @@ func GetRecommendedMovies(client *mongo.Client) gin.HandlerFunc {
 			favoriteGenreIDs = append(favoriteGenreIDs, genre.GenreID)
 		}
 
-		if len(favoriteGenreIDs) == 0 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "No favorite genres found for user"})
-			return
-		}
-
-		// Build query to find movies with matching genres
-		// Using $elemMatch to match any genre in the user's favorite genres
-		genreMatch := bson.M{
-			"genre": bson.M{
-				"$elemMatch": bson.M{
-					"genre_id": bson.M{
-						"$in": favoriteGenreIDs,
-					},
-				},
-			},
-		}
-
-		// Find movies that match user's favorite genres
-		cursor, err := movieCollection.Find(ctx, genreMatch)
+		// Movies the user has already finished are never recommended again
+		finished, err := history.FinishedImdbIDs(ctx, user.UserID)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching recommended movies"})
-			return
-		}
-		defer cursor.Close(ctx)
-
-		var recommendedMovies []models.Movie
-		if err = cursor.All(ctx, &recommendedMovies); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode recommended movies"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching watch history"})
 			return
 		}
 
-		// If no movies found, return popular movies as fallback
-		if len(recommendedMovies) == 0 {
-			// Get movies with high ranking as fallback
-			fallbackQuery := bson.M{
-				"ranking.ranking_value": bson.M{
-					"$gte": 7, // Movies with rating 7 or above
-				},
-			}
-			
-			cursor, err = movieCollection.Find(ctx, fallbackQuery)
+		var recommendedMovies []models.RecommendedMovie
+		if len(favoriteGenreIDs) > 0 {
+			// Candidates are movies with at least one favourite genre; the genre
+			// index keeps this cheap and the scorer does the ordering.
+			genreMatch := repository.MovieFilter{GenreIDs: favoriteGenreIDs, ExcludeImdbIDs: finished}
+			candidates, err := movies.Find(ctx, genreMatch, repository.MovieSort{}, 0)
 			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching fallback movies"})
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching recommended movies"})
 				return
 			}
-			defer cursor.Close(ctx)
 
-			if err = cursor.All(ctx, &recommendedMovies); err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode fallback movies"})
+			rated, err := ratedMovieIDs(ctx, ratings, user.UserID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching user ratings"})
 				return
 			}
+
+			profile := recommendation.Profile{FavouriteGenres: user.FavouriteGenres, Rated: rated}
+			recommendedMovies = recommendation.DefaultScorer().Recommend(profile, candidates, limit)
 		}
 
-		// Limit results to 20 movies for better performance
-		if len(recommendedMovies) > 20 {
-			recommendedMovies = recommendedMovies[:20]
+		// Nothing to personalise on, so fall back to the cold-start strategy
+		if len(recommendedMovies) == 0 {
+			recommendedMovies, err = coldStartRecommendations(ctx, movies, coldStart, finished, limit)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching fallback movies"})
+				return
+			}
 		}
 
 		c.JSON(http.StatusOK, gin.H{
@@ -195,24 +202,34 @@ func GetRecommendedMovies(client *mongo.Client) gin.HandlerFunc {
 }
 
 //--------------------------------------------------------------------------------------------
-// Update admin review for a specific movie (Admin only)
-func AdminReviewUpdate(client *mongo.Client) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
-		defer cancel()
+// Load the rankings a review can be classified into, best first, excluding Not_Ranked
+func loadReviewRankings(ctx context.Context, rankingRepository repository.RankingRepository) ([]models.Ranking, error) {
+	all, err := rankingRepository.List(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-		// Get user role from middleware to check if user is admin
-		userRole, exists := c.Get("role")
-		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "User role not found"})
-			return
+	var rankings []models.Ranking
+	for _, ranking := range all {
+		if ranking.RankingValue != ai.NotRankedValue {
+			rankings = append(rankings, ranking)
 		}
+	}
 
-		// Check if user is admin
-		if userRole != "ADMIN" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied. Admin privileges required"})
-			return
-		}
+	// If no rankings found in database, use the seed rankings
+	if len(rankings) == 0 {
+		return ai.DefaultRankings, nil
+	}
+	return rankings, nil
+}
+
+//--------------------------------------------------------------------------------------------
+// Update admin review for a specific movie. The review is
+// classified into a ranking, and both are written together.
+func AdminReviewUpdate(movies repository.MovieRepository, rankingRepository repository.RankingRepository, classifier ai.ReviewClassifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c)
+		defer cancel()
 
 		// Get movie ID from URL parameter
 		movieID := c.Param("imdb_id")
@@ -238,42 +255,48 @@ func AdminReviewUpdate(client *mongo.Client) gin.HandlerFunc {
 			return
 		}
 
-		// Get movie collection
-		var movieCollection *mongo.Collection = database.OpenCollection("movies", client)
-
 		// Check if movie exists
-		var existingMovie models.Movie
-		err := movieCollection.FindOne(ctx, bson.D{{Key: "imdb_id", Value: movieID}}).Decode(&existingMovie)
+		existingMovie, err := movies.Get(ctx, movieID)
 		if err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
 			return
 		}
 
-		// Update the admin review
-		filter := bson.D{{Key: "imdb_id", Value: movieID}}
-		update := bson.D{
-			{Key: "$set", Value: bson.D{
-				{Key: "admin_review", Value: updateRequest.AdminReview},
-			}},
+		// Classify the review into one of the known rankings
+		rankings, err := loadReviewRankings(ctx, rankingRepository)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load rankings"})
+			return
+		}
+		rankingNames := make([]string, len(rankings))
+		for i, ranking := range rankings {
+			rankingNames[i] = ranking.RankingName
 		}
 
-		result, err := movieCollection.UpdateOne(ctx, filter, update)
+		rankingName, err := classifier.Classify(ctx, updateRequest.AdminReview, rankingNames)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update movie review"})
+			log.Println("Error: failed to classify movie review:", err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to classify movie review"})
 			return
 		}
+		var ranking models.Ranking
+		for _, candidate := range rankings {
+			if candidate.RankingName == rankingName {
+				ranking = candidate
+				break
+			}
+		}
 
-		// Check if any document was modified
-		if result.ModifiedCount == 0 {
+		// Check if anything would change
+		if existingMovie.AdminReview == updateRequest.AdminReview && existingMovie.Ranking == ranking {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "No changes made to the movie review"})
 			return
 		}
 
-		// Fetch the updated movie to return
-		var updatedMovie models.Movie
-		err = movieCollection.FindOne(ctx, filter).Decode(&updatedMovie)
+		// Update the admin review and ranking
+		updatedMovie, err := movies.SetReview(ctx, movieID, updateRequest.AdminReview, ranking)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch updated movie"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update movie review"})
 			return
 		}
 
@@ -286,48 +309,16 @@ func AdminReviewUpdate(client *mongo.Client) gin.HandlerFunc {
 
 //--------------------------------------------------------------------------------------------
 // Get all available genres
-func GetGenres(client *mongo.Client) gin.HandlerFunc {
+func GetGenres(genreRepository repository.GenreRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		ctx, cancel := requestContext(c)
 		defer cancel()
 
-		// Get genres collection
-		var genresCollection *mongo.Collection = database.OpenCollection("genres", client)
-
-		var genres []models.Genre
-
-		cursor, err := genresCollection.Find(ctx, bson.M{})
+		genres, err := genreRepository.List(ctx)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching genres"})
 			return
 		}
-		defer cursor.Close(ctx)
-
-		if err = cursor.All(ctx, &genres); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode genres"})
-			return
-		}
-
-		// If no genres found in database, return default genres
-		if len(genres) == 0 {
-			defaultGenres := []models.Genre{
-				{GenreID: 1, GenreName: "Comedy"},
-				{GenreID: 2, GenreName: "Drama"},
-				{GenreID: 3, GenreName: "Western"},
-				{GenreID: 4, GenreName: "Fantasy"},
-				{GenreID: 5, GenreName: "Thriller"},
-				{GenreID: 6, GenreName: "Sci-Fi"},
-				{GenreID: 7, GenreName: "Action"},
-				{GenreID: 8, GenreName: "Mystery"},
-				{GenreID: 9, GenreName: "Crime"},
-			}
-			c.JSON(http.StatusOK, gin.H{
-				"message": "Genres retrieved successfully",
-				"count":   len(defaultGenres),
-				"genres":  defaultGenres,
-			})
-			return
-		}
 
 		c.JSON(http.StatusOK, gin.H{
 			"message": "Genres retrieved successfully",
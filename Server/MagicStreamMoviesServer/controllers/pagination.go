@@ -0,0 +1,255 @@
+package controllers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	models "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// movieSortFields maps the sort keys accepted by GET /movies to the
+// repository ordering. "created" orders by insertion time.
+var movieSortFields = map[string]repository.SortField{
+	"title":   repository.SortByTitle,
+	"ranking": repository.SortByRanking,
+	"created": repository.SortByCreated,
+}
+
+// pageCursor is the decoded form of the opaque next_cursor token. It records
+// the sort it was issued for together with the sort value and _id of the last
+// item on the previous page, so the next page can resume after it.
+type pageCursor struct {
+	Sort  string             `json:"s"`
+	Value interface{}        `json:"v,omitempty"`
+	ID    primitive.ObjectID `json:"id"`
+}
+
+type movieListQuery struct {
+	repository.MovieQuery
+	// Sort is the sort parameter as given, which cursors are bound to.
+	Sort string
+}
+
+func encodeCursor(cursor pageCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(token string) (*pageCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errors.New("malformed cursor")
+	}
+
+	var cursor pageCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, errors.New("malformed cursor")
+	}
+
+	// JSON numbers come back as float64; ranking values are stored as ints.
+	if number, ok := cursor.Value.(float64); ok {
+		cursor.Value = int(number)
+	}
+	return &cursor, nil
+}
+
+// parseLimit reads the limit query parameter, defaulting to defaultPageLimit.
+func parseLimit(c *gin.Context) (int, error) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultPageLimit)))
+	if err != nil || limit < 1 || limit > maxPageLimit {
+		return 0, fmt.Errorf("limit must be between 1 and %d", maxPageLimit)
+	}
+	return limit, nil
+}
+
+// parseGenreIDs accepts both repeated (?genre_id=1&genre_id=2) and comma
+// separated (?genre_id=1,2) genre filters.
+func parseGenreIDs(c *gin.Context) ([]int, error) {
+	var genreIDs []int
+	for _, raw := range c.QueryArray("genre_id") {
+		for _, part := range strings.Split(raw, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			id, err := strconv.Atoi(part)
+			if err != nil {
+				return nil, fmt.Errorf("invalid genre_id %q", part)
+			}
+			genreIDs = append(genreIDs, id)
+		}
+	}
+	return genreIDs, nil
+}
+
+func parseOptionalInt(c *gin.Context, name string) (*int, error) {
+	raw := c.Query(name)
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an integer", name)
+	}
+	return &value, nil
+}
+
+// parseMovieListQuery turns the GET /movies query string into a repository
+// query.
+func parseMovieListQuery(c *gin.Context) (*movieListQuery, error) {
+	query := &movieListQuery{}
+
+	limit, err := parseLimit(c)
+	if err != nil {
+		return nil, err
+	}
+	query.Limit = limit
+
+	query.Sort = c.DefaultQuery("sort", "created")
+	sortKey := query.Sort
+	if strings.HasPrefix(sortKey, "-") {
+		query.MovieQuery.Sort.Descending = true
+		sortKey = sortKey[1:]
+	}
+	field, ok := movieSortFields[sortKey]
+	if !ok {
+		return nil, fmt.Errorf("sort must be one of title, ranking or created, optionally prefixed with '-'")
+	}
+	query.MovieQuery.Sort.Field = field
+
+	query.Filter.GenreIDs, err = parseGenreIDs(c)
+	if err != nil {
+		return nil, err
+	}
+
+	minRanking, err := parseOptionalInt(c, "min_ranking")
+	if err != nil {
+		return nil, err
+	}
+	maxRanking, err := parseOptionalInt(c, "max_ranking")
+	if err != nil {
+		return nil, err
+	}
+	if minRanking != nil && maxRanking != nil && *minRanking > *maxRanking {
+		return nil, errors.New("min_ranking must not be greater than max_ranking")
+	}
+	query.Filter.MinRanking = minRanking
+	query.Filter.MaxRanking = maxRanking
+	query.Filter.TitlePrefix = c.Query("title_prefix")
+
+	if token := c.Query("cursor"); token != "" {
+		cursor, err := decodeCursor(token)
+		if err != nil {
+			return nil, err
+		}
+		if cursor.ID.IsZero() {
+			return nil, errors.New("malformed cursor")
+		}
+		if cursor.Sort != query.Sort {
+			return nil, errors.New("cursor was issued for a different sort order")
+		}
+		if !cursorValueMatches(field, cursor.Value) {
+			return nil, errors.New("malformed cursor")
+		}
+		query.After = &repository.Cursor{Value: cursor.Value, ID: cursor.ID}
+	}
+
+	return query, nil
+}
+
+// cursorValueMatches reports whether a cursor value has the type the sort
+// field compares by.
+func cursorValueMatches(field repository.SortField, value interface{}) bool {
+	switch field {
+	case repository.SortByTitle:
+		_, ok := value.(string)
+		return ok
+	case repository.SortByRanking:
+		_, ok := value.(int)
+		return ok
+	default:
+		return true
+	}
+}
+
+func (q *movieListQuery) cursorFor(movie models.Movie) string {
+	cursor := pageCursor{Sort: q.Sort, ID: movie.ID}
+	switch q.MovieQuery.Sort.Field {
+	case repository.SortByTitle:
+		cursor.Value = movie.Title
+	case repository.SortByRanking:
+		cursor.Value = movie.Ranking.RankingValue
+	}
+	return encodeCursor(cursor)
+}
+
+// findMoviePage runs the query and wraps the result in the shared pagination
+// envelope.
+func findMoviePage(ctx context.Context, movies repository.MovieRepository, q *movieListQuery) (models.Page[models.Movie], error) {
+	result, err := movies.Page(ctx, q.MovieQuery)
+	if err != nil {
+		return models.Page[models.Movie]{}, err
+	}
+
+	page := models.Page[models.Movie]{Items: result.Items, Total: result.Total}
+	if page.Items == nil {
+		page.Items = []models.Movie{}
+	}
+	if result.More && len(page.Items) > 0 {
+		page.NextCursor = q.cursorFor(page.Items[len(page.Items)-1])
+	}
+	return page, nil
+}
+
+// parseOffsetCursor decodes the cursor of listings that page by offset rather
+// than by key, such as relevance-ranked search results. The cursor must have
+// been issued for the same sort.
+func parseOffsetCursor(c *gin.Context, sort string) (int, error) {
+	token := c.Query("cursor")
+	if token == "" {
+		return 0, nil
+	}
+	cursor, err := decodeCursor(token)
+	if err != nil {
+		return 0, err
+	}
+	offset, ok := cursor.Value.(int)
+	if cursor.Sort != sort || !ok || offset < 0 {
+		return 0, errors.New("malformed cursor")
+	}
+	return offset, nil
+}
+
+// nextOffsetCursor returns the cursor of the page after the one starting at
+// offset, or "" when that page was the last.
+func nextOffsetCursor(sort string, offset, limit int, total int64) string {
+	next := offset + limit
+	if int64(next) >= total {
+		return ""
+	}
+	return encodeCursor(pageCursor{Sort: sort, Value: next})
+}
+
+// offsetPage wraps one page of an offset-paginated listing in the shared
+// envelope.
+func offsetPage[T any](result repository.Page[T], cursorSort string, offset, limit int) models.Page[T] {
+	page := models.Page[T]{Items: result.Items, Total: result.Total}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	page.NextCursor = nextOffsetCursor(cursorSort, offset, limit, result.Total)
+	return page
+}
@@ -0,0 +1,221 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/config"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/loginguard"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/mailer"
+	model "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/repository"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/utils"
+)
+
+// profileResponse strips a user down to what may be shown to them.
+func profileResponse(user model.User) model.ProfileResponse {
+	genres := user.FavouriteGenres
+	if genres == nil {
+		genres = []model.Genre{}
+	}
+	return model.ProfileResponse{
+		UserID:          user.UserID,
+		FirstName:       user.FirstName,
+		LastName:        user.LastName,
+		Email:           user.Email,
+		PendingEmail:    user.PendingEmail,
+		EmailVerified:   user.EmailVerified,
+		Role:            user.Role,
+		Status:          user.AccountStatus(),
+		FavouriteGenres: genres,
+		LockedUntil:     user.LockedUntil,
+		DeletedAt:       user.DeletedAt,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
+	}
+}
+
+//--------------------------------------------------------------------------------------------
+// Get the current user's profile
+func GetProfile(users repository.UserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		user, err := users.GetByID(ctx, c.GetString("userId"))
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		if err != nil {
+			log.Println("Error: failed to fetch user:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+			return
+		}
+
+		c.JSON(http.StatusOK, profileResponse(user))
+	}
+}
+
+//--------------------------------------------------------------------------------------------
+// Update the current user's name, email or password. A new email address
+// only replaces the old one once the link mailed to it is followed. A new
+// password signs out every device, this one included, so the response
+// carries a fresh token pair for this device.
+func UpdateProfile(users repository.UserRepository, userTokens repository.UserTokenRepository, sessions repository.SessionRepository, revocations repository.RevocationRepository, tokens *utils.Tokens, mail mailer.Mailer, account config.Account) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		var update model.ProfileUpdate
+		if err := c.ShouldBindJSON(&update); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input data"})
+			return
+		}
+		validate := validator.New()
+		if err := validate.Struct(update); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+			return
+		}
+
+		user, err := users.GetByID(ctx, c.GetString("userId"))
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		if err != nil {
+			log.Println("Error: failed to fetch user:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+			return
+		}
+
+		changes := repository.UserChanges{FirstName: update.FirstName, LastName: update.LastName}
+		var newEmail string
+		if update.Email != nil {
+			newEmail = strings.TrimSpace(*update.Email)
+			if newEmail == user.Email {
+				// Asking for the current address cancels a pending change
+				newEmail = ""
+			}
+			changes.PendingEmail = &newEmail
+		}
+
+		if (newEmail != "" || update.NewPassword != nil) && !loginguard.CheckPassword(&user, update.CurrentPassword) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Current password is incorrect"})
+			return
+		}
+
+		if newEmail != "" {
+			_, err := users.GetByEmail(ctx, newEmail)
+			if err == nil {
+				c.JSON(http.StatusConflict, gin.H{"error": "Email address is already in use"})
+				return
+			}
+			if !errors.Is(err, repository.ErrNotFound) {
+				log.Println("Error: failed to check email address:", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check email address"})
+				return
+			}
+		}
+
+		var hashedPassword string
+		if update.NewPassword != nil {
+			hashedPassword, err = HashPassword(*update.NewPassword)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+				return
+			}
+		}
+
+		now := time.Now()
+		user, err = users.Update(ctx, user.UserID, changes, now)
+		if err != nil {
+			log.Println("Error: failed to update profile:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
+			return
+		}
+
+		response := gin.H{}
+		if newEmail != "" {
+			if err := sendVerification(ctx, userTokens, mail, account, user, newEmail); err != nil {
+				log.Println("Error: failed to send verification email:", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send verification email"})
+				return
+			}
+			response["message"] = "Follow the link sent to " + newEmail + " to change your email address"
+		}
+
+		if update.NewPassword != nil {
+			if err := users.SetPassword(ctx, user.UserID, hashedPassword, now); err != nil {
+				log.Println("Error: failed to change password:", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change password"})
+				return
+			}
+			if err := revokeUser(ctx, sessions, revocations, tokens, user.UserID, model.RevokedPasswordChanged); err != nil {
+				log.Println("Error: password changed but failed to sign out other devices:", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Password changed but failed to sign out other devices"})
+				return
+			}
+			token, refreshToken, err := issueTokens(ctx, c, sessions, tokens, user, nil)
+			if err != nil {
+				log.Println("Error: password changed but failed to generate new tokens:", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Password changed but failed to generate new tokens"})
+				return
+			}
+			response["token"] = token
+			response["refresh_token"] = refreshToken
+		}
+
+		response["user"] = profileResponse(user)
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+//--------------------------------------------------------------------------------------------
+// Replace the current user's favourite genres, given by ID
+func SetFavouriteGenres(users repository.UserRepository, genres repository.GenreRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		var update model.FavouriteGenresUpdate
+		if err := c.ShouldBindJSON(&update); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input data"})
+			return
+		}
+		validate := validator.New()
+		if err := validate.Struct(update); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+			return
+		}
+
+		favourites, err := resolveGenres(ctx, genres, update.GenreIDs)
+		if errors.Is(err, errInvalidGenre) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid genre", "details": err.Error()})
+			return
+		}
+		if err != nil {
+			log.Println("Error: failed to fetch genres:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch genres"})
+			return
+		}
+
+		user, err := users.Update(ctx, c.GetString("userId"), repository.UserChanges{FavouriteGenres: favourites}, time.Now())
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		if err != nil {
+			log.Println("Error: failed to update favourite genres:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update favourite genres"})
+			return
+		}
+
+		c.JSON(http.StatusOK, profileResponse(user))
+	}
+}
@@ -0,0 +1,237 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	models "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const ratingCursorSort = "-created"
+
+// parseRatingCursor decodes the optional cursor of a ratings listing.
+func parseRatingCursor(c *gin.Context) (*pageCursor, error) {
+	token := c.Query("cursor")
+	if token == "" {
+		return nil, nil
+	}
+	cursor, err := decodeCursor(token)
+	if err != nil {
+		return nil, err
+	}
+	if cursor.Sort != ratingCursorSort || cursor.ID.IsZero() {
+		return nil, errors.New("malformed cursor")
+	}
+	return cursor, nil
+}
+
+// findRatingPage lists ratings matching filter newest first, resuming after
+// the cursor if one is given.
+func findRatingPage(ctx context.Context, ratings repository.RatingRepository, filter repository.RatingFilter, after *pageCursor, limit int) (models.Page[models.Rating], error) {
+	var afterID primitive.ObjectID
+	if after != nil {
+		afterID = after.ID
+	}
+
+	result, err := ratings.Page(ctx, filter, afterID, limit)
+	if err != nil {
+		return models.Page[models.Rating]{}, err
+	}
+
+	page := models.Page[models.Rating]{Items: result.Items, Total: result.Total}
+	if page.Items == nil {
+		page.Items = []models.Rating{}
+	}
+	if result.More && len(page.Items) > 0 {
+		last := page.Items[len(page.Items)-1]
+		page.NextCursor = encodeCursor(pageCursor{Sort: ratingCursorSort, ID: last.ID})
+	}
+	return page, nil
+}
+
+// ratedMovieIDs returns the imdb_ids the user has rated.
+func ratedMovieIDs(ctx context.Context, ratings repository.RatingRepository, userID string) (map[string]bool, error) {
+	ids, err := ratings.RatedImdbIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rated := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		rated[id] = true
+	}
+	return rated, nil
+}
+
+//--------------------------------------------------------------------------------------------
+// Create or update the current user's rating of a movie
+func RateMovie(movies repository.MovieRepository, ratings repository.RatingRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		userID := c.GetString("userId")
+		if userID == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+			return
+		}
+
+		movieID := c.Param("imdb_id")
+		if movieID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Movie ID is required"})
+			return
+		}
+
+		var ratingRequest models.RatingRequest
+		if err := c.ShouldBindJSON(&ratingRequest); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input format"})
+			return
+		}
+		if err := validate.Struct(ratingRequest); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+			return
+		}
+
+		exists, err := movies.Exists(ctx, movieID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check movie"})
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+			return
+		}
+
+		rating, created, err := ratings.Upsert(ctx, models.Rating{
+			UserID:    userID,
+			ImdbID:    movieID,
+			Stars:     ratingRequest.Stars,
+			Review:    ratingRequest.Review,
+			UpdatedAt: time.Now(),
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save rating"})
+			return
+		}
+
+		if err = ratings.SyncAudienceScore(ctx, movieID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update audience score"})
+			return
+		}
+
+		status := http.StatusOK
+		if created {
+			status = http.StatusCreated
+		}
+		c.JSON(status, rating)
+	}
+}
+
+//--------------------------------------------------------------------------------------------
+// Delete the current user's rating of a movie
+func DeleteRating(ratings repository.RatingRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		userID := c.GetString("userId")
+		if userID == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+			return
+		}
+
+		movieID := c.Param("imdb_id")
+		if movieID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Movie ID is required"})
+			return
+		}
+
+		err := ratings.Delete(ctx, userID, movieID)
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Rating not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete rating"})
+			return
+		}
+
+		if err = ratings.SyncAudienceScore(ctx, movieID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update audience score"})
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+//--------------------------------------------------------------------------------------------
+// List the ratings of a movie, newest first
+func GetMovieRatings(ratings repository.RatingRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		movieID := c.Param("imdb_id")
+		if movieID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Movie ID is required"})
+			return
+		}
+
+		limit, err := parseLimit(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+			return
+		}
+		after, err := parseRatingCursor(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+			return
+		}
+
+		page, err := findRatingPage(ctx, ratings, repository.RatingFilter{ImdbID: movieID}, after, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching ratings"})
+			return
+		}
+		c.JSON(http.StatusOK, page)
+	}
+}
+
+//--------------------------------------------------------------------------------------------
+// List the current user's ratings, newest first
+func GetMyRatings(ratings repository.RatingRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		userID := c.GetString("userId")
+		if userID == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+			return
+		}
+
+		limit, err := parseLimit(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+			return
+		}
+		after, err := parseRatingCursor(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+			return
+		}
+
+		page, err := findRatingPage(ctx, ratings, repository.RatingFilter{UserID: userID}, after, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching ratings"})
+			return
+		}
+		c.JSON(http.StatusOK, page)
+	}
+}
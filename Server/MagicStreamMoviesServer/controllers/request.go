@@ -0,0 +1,14 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestContext returns the context handlers pass to the store. It is
+// derived from the request, so it carries the deadline set by
+// middleware.RequestTimeout and ends when the client disconnects.
+func requestContext(c *gin.Context) (context.Context, context.CancelFunc) {
+	return context.WithCancel(c.Request.Context())
+}
@@ -0,0 +1,167 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	models "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/repository"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/search"
+)
+
+const (
+	searchCursorSort    = "relevance"
+	searchSnippetWindow = 8
+)
+
+// newMovieSearchIndex creates the in-process fallback used when the store has
+// no text index. Title matches count most, then genre names, then the admin
+// review.
+func newMovieSearchIndex() *search.Index {
+	return search.NewIndex(map[string]float64{
+		"title":        3,
+		"genre":        2,
+		"admin_review": 1,
+	})
+}
+
+func genreNames(genres []models.Genre) string {
+	names := make([]string, len(genres))
+	for i, genre := range genres {
+		names[i] = genre.GenreName
+	}
+	return strings.Join(names, ", ")
+}
+
+// movieSearchFields returns the searchable text of a movie keyed by the field
+// names reported in highlights.
+func movieSearchFields(movie models.Movie) map[string]string {
+	return map[string]string{
+		"title":        movie.Title,
+		"genre":        genreNames(movie.Genre),
+		"admin_review": movie.AdminReview,
+	}
+}
+
+// highlightMovie marks the query terms in a result's fields the way the
+// in-process index matches them: whole words and, for terms of three or
+// more characters, words they are a prefix of. On the Mongo path the
+// highlights are only approximate, as they are not taken from what $text
+// matched. Its index has no stemming or stop words either, but it does not
+// match prefixes, and a quoted phrase is highlighted word by word.
+func highlightMovie(movie models.Movie, terms []string) map[string]string {
+	highlights := map[string]string{}
+	for field, text := range movieSearchFields(movie) {
+		if snippet, ok := search.Highlight(text, terms, searchSnippetWindow); ok {
+			highlights[field] = snippet
+		}
+	}
+	return highlights
+}
+
+// rebuildMovieSearchIndex loads every movie into the in-process index.
+func rebuildMovieSearchIndex(ctx context.Context, movies repository.MovieRepository, index *search.Index) error {
+	revision := movies.Revision()
+
+	all, err := movies.Find(ctx, repository.MovieFilter{}, repository.MovieSort{}, 0)
+	if err != nil {
+		return err
+	}
+
+	docs := make([]search.Document, len(all))
+	for i, movie := range all {
+		docs[i] = search.Document{ID: movie.ImdbID, Fields: movieSearchFields(movie)}
+	}
+	index.Rebuild(docs, revision)
+	return nil
+}
+
+// searchWithIndex scores movies with the in-process inverted index and loads
+// the requested page from the repository.
+func searchWithIndex(ctx context.Context, movies repository.MovieRepository, index *search.Index, terms []string, offset, limit int) ([]models.MovieSearchResult, int64, error) {
+	if index.Stale(movies.Revision()) {
+		if err := rebuildMovieSearchIndex(ctx, movies, index); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	hits := index.Search(terms)
+	total := int64(len(hits))
+	if offset >= len(hits) {
+		return nil, total, nil
+	}
+	hits = hits[offset:]
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+
+	ids := make([]string, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.ID
+	}
+	byID, err := moviesByID(ctx, movies, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Keep the relevance order; skip movies deleted since the index was built.
+	results := make([]models.MovieSearchResult, 0, len(hits))
+	for _, hit := range hits {
+		if movie, ok := byID[hit.ID]; ok {
+			results = append(results, models.MovieSearchResult{Movie: movie, Score: hit.Score})
+		}
+	}
+	return results, total, nil
+}
+
+//--------------------------------------------------------------------------------------------
+// Search movies by title, genre name and admin review, best matches first
+func SearchMovies(movies repository.MovieRepository) gin.HandlerFunc {
+	index := newMovieSearchIndex()
+
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		q := strings.TrimSpace(c.Query("q"))
+		terms := search.Terms(q)
+		if len(terms) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Search query is required"})
+			return
+		}
+
+		limit, err := parseLimit(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+			return
+		}
+		offset, err := parseOffsetCursor(c, searchCursorSort)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+			return
+		}
+
+		results, total, err := movies.TextSearch(ctx, q, offset, limit)
+		if errors.Is(err, repository.ErrTextSearchUnsupported) {
+			results, total, err = searchWithIndex(ctx, movies, index, terms, offset, limit)
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while searching movies"})
+			return
+		}
+
+		page := models.Page[models.MovieSearchResult]{Items: results, Total: total}
+		if page.Items == nil {
+			page.Items = []models.MovieSearchResult{}
+		}
+		for i := range page.Items {
+			page.Items[i].Highlights = highlightMovie(page.Items[i].Movie, terms)
+		}
+		page.NextCursor = nextOffsetCursor(searchCursorSort, offset, limit, total)
+
+		c.JSON(http.StatusOK, page)
+	}
+}
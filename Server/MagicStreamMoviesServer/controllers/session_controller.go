@@ -0,0 +1,189 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	model "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/repository"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/utils"
+)
+
+// errSessionReused is returned by issueTokens when the session being
+// rotated had already been rotated, i.e. its refresh token was used twice.
+var errSessionReused = errors.New("refresh token reuse detected")
+
+// issueTokens signs a token pair for user and stores the refresh token as a
+// session. With a nil previous it starts a new family for the requesting
+// device; otherwise it rotates previous within its family.
+func issueTokens(ctx context.Context, c *gin.Context, sessions repository.SessionRepository, tokens *utils.Tokens, user model.User, previous *model.Session) (string, string, error) {
+	now := time.Now()
+	session := model.Session{
+		FamilyID:   utils.NewTokenID(),
+		UserID:     user.UserID,
+		UserAgent:  c.Request.UserAgent(),
+		IP:         c.ClientIP(),
+		CreatedAt:  now,
+		SignedInAt: now,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(tokens.RefreshTTL),
+	}
+	if previous != nil {
+		session.FamilyID = previous.FamilyID
+		session.SignedInAt = previous.SignedInAt
+	}
+
+	token, refreshToken, err := tokens.GenerateAllTokens(user.Email, user.FirstName, user.LastName, user.Role, user.UserID, session.FamilyID)
+	if err != nil {
+		return "", "", err
+	}
+	session.RefreshTokenHash = utils.HashToken(refreshToken)
+
+	if previous == nil {
+		err = sessions.Create(ctx, &session)
+	} else {
+		err = sessions.Rotate(ctx, previous.ID, &session, now)
+		if errors.Is(err, repository.ErrNotFound) {
+			err = errSessionReused
+		}
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return token, refreshToken, nil
+}
+
+// revokeSessionTokens blocks the access tokens issued to a session family,
+// which would otherwise keep working until they expire.
+func revokeSessionTokens(ctx context.Context, revocations repository.RevocationRepository, tokens *utils.Tokens, sessionID, reason string) error {
+	now := time.Now()
+	return revocations.Revoke(ctx, model.Revocation{
+		SessionID: sessionID,
+		Reason:    reason,
+		RevokedAt: now,
+		ExpiresAt: now.Add(tokens.AccessTTL),
+	})
+}
+
+// revokeUser signs a user out everywhere: every session is revoked and every
+// access token issued before now stops working. Logout, a password change
+// and a role change all call it. Issue times only have whole seconds, so
+// tokens from earlier seconds are matched by iat and those from this second
+// by the sessions just revoked; tokens issued right after, such as for the
+// new password, belong to a new session and stay valid.
+func revokeUser(ctx context.Context, sessions repository.SessionRepository, revocations repository.RevocationRepository, tokens *utils.Tokens, userID, reason string) error {
+	now := time.Now()
+	sessionIDs, err := sessions.RevokeUser(ctx, userID, reason, now)
+	if err != nil {
+		return err
+	}
+
+	issuedBefore := now.Truncate(time.Second)
+	return revocations.Revoke(ctx, model.Revocation{
+		UserID:       userID,
+		IssuedBefore: &issuedBefore,
+		SessionIDs:   sessionIDs,
+		Reason:       reason,
+		RevokedAt:    now,
+		ExpiresAt:    now.Add(tokens.AccessTTL),
+	})
+}
+
+// logoutDevice revokes one session and the access tokens issued to it. An
+// access token without a session claim is revoked by its jti alone.
+func logoutDevice(ctx context.Context, sessions repository.SessionRepository, revocations repository.RevocationRepository, tokens *utils.Tokens, userID, sessionID, tokenID string) error {
+	now := time.Now()
+	if sessionID != "" {
+		err := sessions.RevokeFamily(ctx, userID, sessionID, model.SessionLoggedOut, now)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+		return revokeSessionTokens(ctx, revocations, tokens, sessionID, model.SessionLoggedOut)
+	}
+	if tokenID == "" {
+		return nil
+	}
+	return revocations.Revoke(ctx, model.Revocation{
+		TokenID:   tokenID,
+		Reason:    model.SessionLoggedOut,
+		RevokedAt: now,
+		ExpiresAt: now.Add(tokens.AccessTTL),
+	})
+}
+
+// revokeReusedFamily signs out every device in the family of a session
+// whose refresh token was presented after it had been rotated: either the
+// client or an attacker holds a stolen copy, and there is no telling which.
+func revokeReusedFamily(ctx context.Context, sessions repository.SessionRepository, revocations repository.RevocationRepository, tokens *utils.Tokens, session model.Session) {
+	err := sessions.RevokeFamily(ctx, session.UserID, session.FamilyID, model.SessionReused, time.Now())
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		log.Println("Warning: unable to revoke reused session family:", err)
+		return
+	}
+	if err := revokeSessionTokens(ctx, revocations, tokens, session.FamilyID, model.SessionReused); err != nil {
+		log.Println("Warning: unable to revoke access tokens of reused session family:", err)
+	}
+	log.Printf("Refresh token reuse detected for user %s, session %s revoked", session.UserID, session.FamilyID)
+}
+
+//--------------------------------------------------------------------------------------------
+// List the devices the current user is signed in on, most recently used first
+func GetSessions(sessions repository.SessionRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		active, err := sessions.ListActive(ctx, c.GetString("userId"), time.Now())
+		if err != nil {
+			log.Println("Error: failed to fetch sessions:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+			return
+		}
+
+		current := c.GetString("sessionId")
+		response := make([]model.SessionResponse, len(active))
+		for i, session := range active {
+			response[i] = model.SessionResponse{
+				SessionID:  session.FamilyID,
+				UserAgent:  session.UserAgent,
+				IP:         session.IP,
+				SignedInAt: session.SignedInAt,
+				LastUsedAt: session.LastUsedAt,
+				ExpiresAt:  session.ExpiresAt,
+				Current:    session.FamilyID == current,
+			}
+		}
+		c.JSON(http.StatusOK, gin.H{"sessions": response})
+	}
+}
+
+//--------------------------------------------------------------------------------------------
+// Sign the current user out of one device. Its refresh and access tokens
+// stop working immediately.
+func DeleteSession(sessions repository.SessionRepository, revocations repository.RevocationRepository, tokens *utils.Tokens) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		sessionID := c.Param("session_id")
+		err := sessions.RevokeFamily(ctx, c.GetString("userId"), sessionID, model.SessionLoggedOut, time.Now())
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			return
+		}
+		if err == nil {
+			err = revokeSessionTokens(ctx, revocations, tokens, sessionID, model.SessionLoggedOut)
+		}
+		if err != nil {
+			log.Println("Error: failed to revoke session:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -2,22 +2,25 @@ package controllers
 
 import (
 	"context"
+	"errors"
+	"log"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
-	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/database"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/config"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/loginguard"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/mailer"
 	model "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
-	"go.mongodb.org/mongo-driver/bson"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/repository"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 	"golang.org/x/crypto/bcrypt"
 	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/utils"
 )
 
-var userCollection *mongo.Collection = database.OpenCollection("users", client)
-
 func HashPassword(password string) (string, error) {
 	HashPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
@@ -28,56 +31,83 @@ func HashPassword(password string) (string, error) {
 
 }
 
-func RegisterUser() gin.HandlerFunc {
+// tooManyLoginAttempts answers a throttled or locked login. Both get the same
+// answer, so it does not reveal whether the address has an account.
+func tooManyLoginAttempts(c *gin.Context, wait time.Duration) {
+	seconds := int(math.Ceil(wait.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many failed login attempts, try again later", "retry_after": seconds})
+}
+
+// RegisterUser creates a USER account and mails a link to verify its
+// address. Admins are made with cmd/admin or promoted by another admin.
+// The account is created even if the email cannot be sent; the user can ask
+// for another one.
+func RegisterUser(users repository.UserRepository, genres repository.GenreRepository, userTokens repository.UserTokenRepository, mail mailer.Mailer, account config.Account) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var user model.User
+		var request model.RegisterRequest
 
-		if err := c.ShouldBind(&user); err != nil {
+		if err := c.ShouldBind(&request); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input Data"})
 			return
 		}
 		validate := validator.New()
-		if err := validate.Struct(user); err != nil {
+		if err := validate.Struct(request); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
 			return
 		}
 
-		hashedPassword, err := HashPassword(user.Password)
-		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		hashedPassword, err := HashPassword(request.Password)
+		ctx, cancel := requestContext(c)
 		defer cancel()
 
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
 			return
 		}
-
-		count, err := userCollection.CountDocuments(ctx, bson.M{"email": user.Email})
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check existing user"})
+		if err := validateGenres(ctx, genres, request.FavouriteGenres); err != nil {
+			genreError(c, err)
 			return
 		}
-		if count > 0 {
+
+		now := time.Now()
+		user := model.User{
+			UserID:          primitive.NewObjectID().Hex(),
+			FirstName:       request.FirstName,
+			LastName:        request.LastName,
+			Email:           request.Email,
+			Password:        hashedPassword,
+			Role:            "USER",
+			Status:          model.UserActive,
+			FavouriteGenres: request.FavouriteGenres,
+			CreatedAt:       now,
+			UpdatedAt:       now,
+		}
+
+		err = users.Insert(ctx, &user)
+		if errors.Is(err, repository.ErrDuplicate) {
 			c.JSON(http.StatusConflict, gin.H{"error": "User already exists"})
 			return
 		}
-
-		user.UserID = primitive.NewObjectID().Hex()
-		user.CreatedAt = time.Now()
-		user.UpdatedAt = time.Now()
-		user.Password = hashedPassword
-
-		result, err := userCollection.InsertOne(ctx, user)
-		
 		if err != nil{
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
 			return
 		}
 
-		c.JSON(http.StatusCreated, result)
+		inBackground(ctx, "verification email", func(ctx context.Context) error {
+			return sendVerification(ctx, userTokens, mail, account, user, user.Email)
+		})
+
+		c.JSON(http.StatusCreated, gin.H{"InsertedID": user.ID})
 	}
 }
 
-func LoginUser(client *mongo.Client) gin.HandlerFunc {
+// LoginUser checks the credentials and starts a session. Repeated failures
+// are throttled by guard, and unknown addresses are answered exactly like
+// wrong passwords. With account.RequireVerifiedEmail set, users must have
+// verified their address first; the check comes after the password so it
+// reveals nothing to someone who does not know it.
+func LoginUser(users repository.UserRepository, sessions repository.SessionRepository, tokens *utils.Tokens, guard *loginguard.Guard, account config.Account) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var userLogin model.UserLogin
 
@@ -86,35 +116,65 @@ func LoginUser(client *mongo.Client) gin.HandlerFunc {
 			return
 		}
 
-		var ctx, cancel = context.WithTimeout(c, 100*time.Second)
+		ctx, cancel := requestContext(c)
 		defer cancel()
 
-		var userCollection *mongo.Collection = database.OpenCollection("users", client)
-
-		var foundUser model.User
-		err := userCollection.FindOne(ctx, bson.D{{Key: "email", Value: userLogin.Email}}).Decode(&foundUser)
+		now := time.Now()
+		wait, err := guard.Wait(ctx, userLogin.Email, c.ClientIP(), now)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+			log.Println("Error: failed to check login attempts:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check login attempts"})
+			return
+		}
+		if wait > 0 {
+			tooManyLoginAttempts(c, wait)
 			return
 		}
 
-		err = bcrypt.CompareHashAndPassword([]byte(foundUser.Password), []byte(userLogin.Password))
-		if err != nil {
+		var user *model.User
+		foundUser, err := users.GetByEmail(ctx, userLogin.Email)
+		if err == nil && foundUser.AccountStatus() != model.UserDeleted {
+			user = &foundUser
+		} else if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			log.Println("Error: failed to fetch user:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+			return
+		}
+
+		// A locked account is refused before the password is checked, so the
+		// answer is the same whether or not the password was right
+		if user != nil {
+			if locked := loginguard.LockedFor(*user, now); locked > 0 {
+				tooManyLoginAttempts(c, locked)
+				return
+			}
+		}
+
+		if !loginguard.CheckPassword(user, userLogin.Password) {
+			if err := guard.Failed(ctx, userLogin.Email, c.ClientIP(), user, now); err != nil {
+				log.Println("Warning: unable to record failed login:", err)
+			}
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
 			return
 		}
 
-		token, refreshToken, err := utils.GenerateAllTokens(foundUser.Email, foundUser.FirstName, foundUser.LastName, foundUser.Role, foundUser.UserID)
+		if foundUser.AccountStatus() == model.UserDisabled {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Account is disabled"})
+			return
+		}
+		if err := guard.Succeeded(ctx, userLogin.Email); err != nil {
+			log.Println("Warning: unable to reset failed logins:", err)
+		}
 
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate tokens"})
+		if account.RequireVerifiedEmail && !foundUser.EmailVerified {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Email address not verified"})
 			return
 		}
 
-		err = utils.UpdateAllTokens(foundUser.UserID, token, refreshToken, client)
+		token, refreshToken, err := issueTokens(ctx, c, sessions, tokens, foundUser, nil)
 
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update tokens"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate tokens"})
 			return
 		}
 		// http.SetCookie(c.Writer, &http.Cookie{
@@ -153,80 +213,67 @@ func LoginUser(client *mongo.Client) gin.HandlerFunc {
 }
 
 //--------------------------------------------------------------------------------------------
-// Logout user by clearing tokens from database
-func LogoutHandler(client *mongo.Client) gin.HandlerFunc {
+// Logout the device the bearer token or refresh token was issued to, or
+// every device with "everywhere": true. The user is taken from the token, never
+// from the request. Logging out is idempotent: it answers 204 even when the
+// tokens were already revoked, expired or missing.
+func LogoutHandler(sessions repository.SessionRepository, revocations repository.RevocationRepository, tokens *utils.Tokens) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		ctx, cancel := requestContext(c)
 		defer cancel()
 
-		// Get user ID from the request (could be from token or request body)
 		var logoutRequest struct {
-			UserID string `json:"user_id" validate:"required"`
+			RefreshToken string `json:"refresh_token"`
+			Everywhere   bool   `json:"everywhere"`
+		}
+		// The body is optional
+		_ = c.ShouldBindJSON(&logoutRequest)
+		if c.Query("everywhere") == "true" {
+			logoutRequest.Everywhere = true
 		}
 
-		// Try to bind JSON first
-		if err := c.ShouldBindJSON(&logoutRequest); err != nil {
-			// If JSON binding fails, try to get user_id from query parameter
-			userID := c.Query("user_id")
-			if userID == "" {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "User ID is required"})
-				return
+		var userID, sessionID, tokenID string
+		if token, err := utils.GetAccessToken(c); err == nil {
+			if claims, err := tokens.ValidateToken(token); err == nil {
+				userID, sessionID, tokenID = claims.UserID, claims.SessionID, claims.ID
 			}
-			logoutRequest.UserID = userID
 		}
-
-		// Validate user ID
-		validate := validator.New()
-		if err := validate.Struct(logoutRequest); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID", "details": err.Error()})
-			return
+		if userID == "" && logoutRequest.RefreshToken != "" {
+			claims, err := tokens.ValidateRefreshToken(logoutRequest.RefreshToken)
+			if err == nil {
+				session, err := sessions.GetByTokenHash(ctx, utils.HashToken(logoutRequest.RefreshToken))
+				if err == nil && session.UserID == claims.UserID {
+					userID, sessionID = session.UserID, session.FamilyID
+				}
+			}
 		}
-
-		// Get user collection
-		var userCollection *mongo.Collection = database.OpenCollection("users", client)
-
-		// Check if user exists
-		var existingUser model.User
-		err := userCollection.FindOne(ctx, bson.D{{Key: "user_id", Value: logoutRequest.UserID}}).Decode(&existingUser)
-		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		if userID == "" {
+			c.Status(http.StatusNoContent)
 			return
 		}
 
-		// Clear tokens by setting them to empty strings
-		filter := bson.D{{Key: "user_id", Value: logoutRequest.UserID}}
-		update := bson.D{
-			{Key: "$set", Value: bson.D{
-				{Key: "token", Value: ""},
-				{Key: "refresh_token", Value: ""},
-				{Key: "update_at", Value: time.Now()},
-			}},
+		var err error
+		if logoutRequest.Everywhere {
+			err = revokeUser(ctx, sessions, revocations, tokens, userID, model.SessionLoggedOut)
+		} else {
+			err = logoutDevice(ctx, sessions, revocations, tokens, userID, sessionID, tokenID)
 		}
-
-		result, err := userCollection.UpdateOne(ctx, filter, update)
 		if err != nil {
+			log.Println("Error: failed to logout user:", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to logout user"})
 			return
 		}
 
-		// Check if any document was modified
-		if result.ModifiedCount == 0 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "No changes made during logout"})
-			return
-		}
-
-		c.JSON(http.StatusOK, gin.H{
-			"message": "User logged out successfully",
-			"user_id": logoutRequest.UserID,
-		})
+		c.Status(http.StatusNoContent)
 	}
 }
 
 //--------------------------------------------------------------------------------------------
-// Refresh access token using refresh token
-func RefreshTokenHandler(client *mongo.Client) gin.HandlerFunc {
+// Refresh access token using refresh token. The refresh token is rotated:
+// the old one stops working, and presenting it again signs the device out.
+func RefreshTokenHandler(users repository.UserRepository, sessions repository.SessionRepository, revocations repository.RevocationRepository, tokens *utils.Tokens) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+		ctx, cancel := requestContext(c)
 		defer cancel()
 
 		// Define request structure for refresh token
@@ -248,45 +295,43 @@ func RefreshTokenHandler(client *mongo.Client) gin.HandlerFunc {
 		}
 
 		// Validate the refresh token
-		claims, err := utils.ValidateRefreshToken(refreshRequest.RefreshToken)
+		claims, err := tokens.ValidateRefreshToken(refreshRequest.RefreshToken)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
 			return
 		}
 
-		// Get user collection
-		var userCollection *mongo.Collection = database.OpenCollection("users", client)
-
-		// Check if user exists and refresh token matches
-		var foundUser model.User
-		err = userCollection.FindOne(ctx, bson.D{
-			{Key: "user_id", Value: claims.UserID},
-			{Key: "refresh_token", Value: refreshRequest.RefreshToken},
-		}).Decode(&foundUser)
-
-		if err != nil {
+		// Check the refresh token belongs to a session of the user
+		session, err := sessions.GetByTokenHash(ctx, utils.HashToken(refreshRequest.RefreshToken))
+		if err != nil || session.UserID != claims.UserID {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token or user not found"})
 			return
 		}
+		if session.RevokedAt != nil {
+			if session.RevokedReason == model.SessionRotated {
+				revokeReusedFamily(ctx, sessions, revocations, tokens, session)
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token has already been used; the session was signed out"})
+				return
+			}
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been signed out"})
+			return
+		}
 
-		// Generate new tokens
-		newToken, newRefreshToken, err := utils.GenerateAllTokens(
-			foundUser.Email,
-			foundUser.FirstName,
-			foundUser.LastName,
-			foundUser.Role,
-			foundUser.UserID,
-		)
-
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate new tokens"})
+		foundUser, err := users.GetByID(ctx, claims.UserID)
+		if err != nil || foundUser.AccountStatus() != model.UserActive {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token or user not found"})
 			return
 		}
 
-		// Update tokens in database
-		err = utils.UpdateAllTokens(foundUser.UserID, newToken, newRefreshToken, client)
+		// Generate new tokens and rotate the session
+		newToken, newRefreshToken, err := issueTokens(ctx, c, sessions, tokens, foundUser, &session)
+		if errors.Is(err, errSessionReused) {
+			revokeReusedFamily(ctx, sessions, revocations, tokens, session)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token has already been used; the session was signed out"})
+			return
+		}
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update tokens"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate new tokens"})
 			return
 		}
 
@@ -0,0 +1,270 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	models "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/repository"
+)
+
+const (
+	watchlistCursorSort = "-added_at"
+	historyCursorSort   = "-last_watched_at"
+)
+
+// moviesByID loads the movies with the given imdb_ids keyed by imdb_id.
+func moviesByID(ctx context.Context, movies repository.MovieRepository, ids []string) (map[string]models.Movie, error) {
+	found, err := movies.FindByImdbIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]models.Movie, len(found))
+	for _, movie := range found {
+		byID[movie.ImdbID] = movie
+	}
+	return byID, nil
+}
+
+//--------------------------------------------------------------------------------------------
+// List the current user's watchlist, most recently added first
+func GetWatchlist(watchlist repository.WatchlistRepository, movies repository.MovieRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		userID := c.GetString("userId")
+		if userID == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+			return
+		}
+
+		limit, err := parseLimit(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+			return
+		}
+		offset, err := parseOffsetCursor(c, watchlistCursorSort)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+			return
+		}
+
+		result, err := watchlist.Page(ctx, userID, offset, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching watchlist"})
+			return
+		}
+		page := offsetPage(result, watchlistCursorSort, offset, limit)
+
+		ids := make([]string, len(page.Items))
+		for i, item := range page.Items {
+			ids[i] = item.ImdbID
+		}
+		byID, err := moviesByID(ctx, movies, ids)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching watchlist movies"})
+			return
+		}
+		for i := range page.Items {
+			if movie, ok := byID[page.Items[i].ImdbID]; ok {
+				page.Items[i].Movie = &movie
+			}
+		}
+
+		c.JSON(http.StatusOK, page)
+	}
+}
+
+//--------------------------------------------------------------------------------------------
+// Add a movie to the current user's watchlist. Adding it again is a no-op.
+func AddToWatchlist(watchlist repository.WatchlistRepository, movies repository.MovieRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		userID := c.GetString("userId")
+		if userID == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+			return
+		}
+
+		var watchlistRequest models.WatchlistRequest
+		if err := c.ShouldBindJSON(&watchlistRequest); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input format"})
+			return
+		}
+		if err := validate.Struct(watchlistRequest); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+			return
+		}
+
+		exists, err := movies.Exists(ctx, watchlistRequest.ImdbID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check movie"})
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+			return
+		}
+
+		item, created, err := watchlist.Add(ctx, userID, watchlistRequest.ImdbID, time.Now())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update watchlist"})
+			return
+		}
+
+		status := http.StatusOK
+		if created {
+			status = http.StatusCreated
+		}
+		c.JSON(status, item)
+	}
+}
+
+//--------------------------------------------------------------------------------------------
+// Remove a movie from the current user's watchlist
+func RemoveFromWatchlist(watchlist repository.WatchlistRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		userID := c.GetString("userId")
+		if userID == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+			return
+		}
+
+		movieID := c.Param("imdb_id")
+		if movieID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Movie ID is required"})
+			return
+		}
+
+		err := watchlist.Remove(ctx, userID, movieID)
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie is not on the watchlist"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update watchlist"})
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+//--------------------------------------------------------------------------------------------
+// Record a playback event for the current user
+func RecordPlayback(history repository.WatchHistoryRepository, movies repository.MovieRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		userID := c.GetString("userId")
+		if userID == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+			return
+		}
+
+		var event models.PlaybackEvent
+		if err := c.ShouldBindJSON(&event); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input format"})
+			return
+		}
+		if err := validate.Struct(event); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+			return
+		}
+
+		exists, err := movies.Exists(ctx, event.ImdbID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check movie"})
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+			return
+		}
+
+		entry, created, err := history.Record(ctx, userID, event, time.Now())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record playback"})
+			return
+		}
+
+		status := http.StatusOK
+		if created {
+			status = http.StatusCreated
+		}
+		c.JSON(status, entry)
+	}
+}
+
+//--------------------------------------------------------------------------------------------
+// List the current user's watch history, most recently watched first. Pass
+// status=in_progress for "continue watching" or status=finished.
+func GetWatchHistory(history repository.WatchHistoryRepository, movies repository.MovieRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+
+		userID := c.GetString("userId")
+		if userID == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+			return
+		}
+
+		var finished *bool
+		switch status := c.Query("status"); status {
+		case "":
+		case "in_progress", "finished":
+			wantFinished := status == "finished"
+			finished = &wantFinished
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": "status must be in_progress or finished"})
+			return
+		}
+
+		limit, err := parseLimit(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+			return
+		}
+		offset, err := parseOffsetCursor(c, historyCursorSort)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+			return
+		}
+
+		result, err := history.Page(ctx, userID, finished, offset, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching watch history"})
+			return
+		}
+		page := offsetPage(result, historyCursorSort, offset, limit)
+
+		ids := make([]string, len(page.Items))
+		for i, entry := range page.Items {
+			ids[i] = entry.ImdbID
+		}
+		byID, err := moviesByID(ctx, movies, ids)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching watch history movies"})
+			return
+		}
+		for i := range page.Items {
+			if movie, ok := byID[page.Items[i].ImdbID]; ok {
+				page.Items[i].Movie = &movie
+			}
+		}
+
+		c.JSON(http.StatusOK, page)
+	}
+}
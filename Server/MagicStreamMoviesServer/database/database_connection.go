@@ -1,29 +1,17 @@
 package database 
 
 import (
-	"fmt"
-	"log"
-	"os"
 	"time"
 	"context"
+	"log"
 
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
-	"github.com/joho/godotenv"
 )
 
-func Connect() *mongo.Client {
-    if err := godotenv.Load(".env"); err != nil {
-        log.Println("Warning: unable to find .env file")
-    }
-
-    MongoDb := os.Getenv("MONGODB_URI")
-    if MongoDb == "" {
-        MongoDb = "mongodb://localhost:27017"
-        log.Println("Using default MongoDB URI:", MongoDb)
-    }
-
-    clientOptions := options.Client().ApplyURI(MongoDb)
+// Connect opens a client for uri and checks the server answers.
+func Connect(uri string) *mongo.Client {
+    clientOptions := options.Client().ApplyURI(uri)
 
     ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
     defer cancel()
@@ -43,26 +31,13 @@ func Connect() *mongo.Client {
 
 //var Client *mongo.Client = DBInstance()
 
-func OpenCollection(collectionName string, client *mongo.Client) *mongo.Collection {
+func OpenCollection(collectionName string, db *mongo.Database) *mongo.Collection {
 
-	err := godotenv.Load(".env")
-	if err != nil {
-		log.Println("Warning: unable to find .env file")
-	}
-
-	databaseName := os.Getenv("DATABASE_NAME")
-	if databaseName == "" {
-		databaseName = "MagicStreamMovies"
-		log.Println("Using default database name:", databaseName)
-	}
-
-	fmt.Println("DATABASE_NAME: ", databaseName)
-
-	collection := client.Database(databaseName).Collection(collectionName)
+	collection := db.Collection(collectionName)
 
 	if collection == nil {
 		return nil
 	}
 	return collection
 
-}
\ No newline at end of file
+}
@@ -0,0 +1,88 @@
+// Package fixtures reads the JSON files in MagicStreamSeedData. They are
+// arrays of documents that may use MongoDB extended JSON such as
+// {"$date": "..."}.
+package fixtures
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/go-playground/validator/v10"
+	models "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var validate = validator.New()
+
+// Set is the content of a fixture directory.
+type Set struct {
+	Genres   []models.Genre
+	Rankings []models.Ranking
+	Movies   []models.Movie
+	Users    []models.User
+}
+
+// Parse splits an extended JSON array into its documents.
+func Parse(data []byte) ([]bson.Raw, error) {
+	// Extended JSON can only be unmarshalled into a document, so wrap the
+	// top-level array in one.
+	wrapped := append(append([]byte(`{"items":`), data...), '}')
+
+	var raw struct {
+		Items []bson.Raw `bson:"items"`
+	}
+	if err := bson.UnmarshalExtJSON(wrapped, false, &raw); err != nil {
+		return nil, err
+	}
+	return raw.Items, nil
+}
+
+// loadFile decodes and validates every document of dir/file as a T. A
+// missing file yields no documents.
+func loadFile[T any](dir, file string) ([]T, error) {
+	data, err := os.ReadFile(filepath.Join(dir, file))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	docs, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", file, err)
+	}
+
+	items := make([]T, len(docs))
+	for i, doc := range docs {
+		if err := bson.Unmarshal(doc, &items[i]); err != nil {
+			return nil, fmt.Errorf("%s[%d]: %w", file, i, err)
+		}
+		if err := validate.Struct(items[i]); err != nil {
+			return nil, fmt.Errorf("%s[%d]: %w", file, i, err)
+		}
+	}
+	return items, nil
+}
+
+// Load reads genres.json, rankings.json, movies.json and users.json from dir.
+// Files that do not exist are treated as empty.
+func Load(dir string) (Set, error) {
+	var set Set
+	var err error
+	if set.Genres, err = loadFile[models.Genre](dir, "genres.json"); err != nil {
+		return set, err
+	}
+	if set.Rankings, err = loadFile[models.Ranking](dir, "rankings.json"); err != nil {
+		return set, err
+	}
+	if set.Movies, err = loadFile[models.Movie](dir, "movies.json"); err != nil {
+		return set, err
+	}
+	if set.Users, err = loadFile[models.User](dir, "users.json"); err != nil {
+		return set, err
+	}
+	return set, nil
+}
@@ -0,0 +1,114 @@
+// Package health tracks whether the server should receive traffic: the
+// dependencies it needs must answer and it must not be shutting down.
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Check reports whether a dependency is usable.
+type Check func(ctx context.Context) error
+
+// Status is the outcome of one check.
+type Status struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report is the readiness of the server. Ready is false while draining or
+// when any check failed.
+type Report struct {
+	Ready    bool              `json:"-"`
+	Status   string            `json:"status"`
+	Draining bool              `json:"draining"`
+	Checks   map[string]Status `json:"checks"`
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Probe runs the readiness checks. The zero value is ready and has no
+// checks.
+type Probe struct {
+	mu       sync.RWMutex
+	checks   []namedCheck
+	draining atomic.Bool
+}
+
+// Register adds a dependency check under name.
+func (p *Probe) Register(name string, check Check) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.checks = append(p.checks, namedCheck{name, check})
+}
+
+// Drain marks the server as shutting down, so readiness fails and load
+// balancers stop routing to it while in-flight requests finish.
+func (p *Probe) Drain() {
+	p.draining.Store(true)
+}
+
+// Draining reports whether Drain has been called.
+func (p *Probe) Draining() bool {
+	return p.draining.Load()
+}
+
+// Check runs every registered check concurrently, each bounded by timeout.
+func (p *Probe) Check(ctx context.Context, timeout time.Duration) Report {
+	p.mu.RLock()
+	checks := append([]namedCheck{}, p.checks...)
+	p.mu.RUnlock()
+
+	statuses := make([]Status, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			statuses[i] = run(ctx, check.check, timeout)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{
+		Ready:    !p.Draining(),
+		Draining: p.Draining(),
+		Checks:   make(map[string]Status, len(checks)),
+	}
+	for i, check := range checks {
+		report.Checks[check.name] = statuses[i]
+		if statuses[i].Status != "up" {
+			report.Ready = false
+		}
+	}
+
+	switch {
+	case report.Draining:
+		report.Status = "draining"
+	case report.Ready:
+		report.Status = "ready"
+	default:
+		report.Status = "unavailable"
+	}
+	return report
+}
+
+func run(ctx context.Context, check Check, timeout time.Duration) Status {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	status := Status{Status: "up", LatencyMS: float64(time.Since(start).Microseconds()) / 1000}
+	if err != nil {
+		status.Status = "down"
+		status.Error = err.Error()
+	}
+	return status
+}
@@ -0,0 +1,229 @@
+// Package jwks holds the keys tokens are signed with. A key set has one
+// signing key plus any number of keys that only verify, so keys can be
+// rotated without logging everyone out: add a new key, make it the signing
+// key, and retire the old one once the tokens it signed have expired.
+package jwks
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Supported signing algorithms, named as in the JWT alg header.
+const (
+	RS256 = "RS256"
+	EdDSA = "EdDSA"
+)
+
+// Key is one key of the set. Private is nil for keys only held to verify.
+type Key struct {
+	ID        string
+	Algorithm string
+	Public    crypto.PublicKey
+	Private   crypto.Signer
+	// Retiring keys still verify tokens but never sign new ones.
+	Retiring bool
+}
+
+// KeySet is the keys tokens may be verified with and the one new tokens
+// are signed with.
+type KeySet struct {
+	keys    map[string]*Key
+	signing *Key
+}
+
+// Options controls how a key set is loaded.
+type Options struct {
+	// Dir holds one PEM file per key, named <kid>.pem. Private keys may be
+	// PKCS#8 (RSA or Ed25519) or PKCS#1 (RSA); a file with only a public key
+	// can verify but not sign.
+	Dir string
+	// Algorithm is the algorithm of generated keys and of the default
+	// signing key.
+	Algorithm string
+	// SigningKey is the kid to sign with. When empty the last active key of
+	// Algorithm in kid order signs, which for generated kids is the newest.
+	SigningKey string
+	// Retiring lists kids that only verify.
+	Retiring []string
+	// Generate creates a key in Dir when it holds no private key.
+	Generate bool
+}
+
+// Load reads the key set from opts.Dir, generating its first key if allowed.
+func Load(opts Options) (*KeySet, error) {
+	if opts.Algorithm != RS256 && opts.Algorithm != EdDSA {
+		return nil, fmt.Errorf("unsupported signing algorithm %q", opts.Algorithm)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(opts.Dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	set := &KeySet{keys: map[string]*Key{}}
+	for _, path := range paths {
+		key, err := readKey(path)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", path, err)
+		}
+		key.Retiring = key.Private == nil || slices.Contains(opts.Retiring, key.ID)
+		set.keys[key.ID] = key
+	}
+
+	if !set.hasActive() {
+		if !opts.Generate {
+			return nil, fmt.Errorf("no signing key in %s", opts.Dir)
+		}
+		key, err := generate(opts.Dir, opts.Algorithm)
+		if err != nil {
+			return nil, fmt.Errorf("generating signing key: %w", err)
+		}
+		log.Printf("Generated %s signing key %s in %s", key.Algorithm, key.ID, opts.Dir)
+		set.keys[key.ID] = key
+	}
+
+	if err := set.chooseSigningKey(opts); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+func (s *KeySet) hasActive() bool {
+	for _, key := range s.keys {
+		if !key.Retiring {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *KeySet) chooseSigningKey(opts Options) error {
+	if opts.SigningKey != "" {
+		key, ok := s.keys[opts.SigningKey]
+		if !ok || key.Retiring {
+			return fmt.Errorf("signing key %q is not an active private key in %s", opts.SigningKey, opts.Dir)
+		}
+		s.signing = key
+		return nil
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(s.keys)) {
+		if key := s.keys[id]; !key.Retiring && key.Algorithm == opts.Algorithm {
+			s.signing = key
+		}
+	}
+	if s.signing == nil {
+		return fmt.Errorf("no active %s key in %s", opts.Algorithm, opts.Dir)
+	}
+	return nil
+}
+
+// SigningKey returns the key new tokens are signed with.
+func (s *KeySet) SigningKey() *Key {
+	return s.signing
+}
+
+// Lookup finds a key by kid.
+func (s *KeySet) Lookup(kid string) (*Key, bool) {
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+func readKey(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block")
+	}
+
+	key := &Key{ID: strings.TrimSuffix(filepath.Base(path), ".pem")}
+	var parsed interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Algorithm, key.Private, key.Public = RS256, k, &k.PublicKey
+	case ed25519.PrivateKey:
+		key.Algorithm, key.Private, key.Public = EdDSA, k, k.Public()
+	case *rsa.PublicKey:
+		key.Algorithm, key.Public = RS256, k
+	case ed25519.PublicKey:
+		key.Algorithm, key.Public = EdDSA, k
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+	if rsaKey, ok := key.Public.(*rsa.PublicKey); ok && rsaKey.N.BitLen() < 2048 {
+		return nil, errors.New("RSA keys must be at least 2048 bits")
+	}
+	return key, nil
+}
+
+// generate creates a key and saves it to dir as a PKCS#8 file readable only
+// by the owner. The kid is the creation time, so kid order is age order.
+func generate(dir, algorithm string) (*Key, error) {
+	key := &Key{
+		ID:        time.Now().UTC().Format("20060102T150405Z"),
+		Algorithm: algorithm,
+	}
+	switch algorithm {
+	case RS256:
+		private, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, err
+		}
+		key.Private, key.Public = private, &private.PublicKey
+	case EdDSA:
+		public, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		key.Private, key.Public = private, public
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key.Private)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, key.ID+".pem")
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	if err := pem.Encode(file, &pem.Block{Type: "PRIVATE KEY", Bytes: der}); err != nil {
+		return nil, err
+	}
+	return key, file.Close()
+}
@@ -0,0 +1,159 @@
+// Package loginguard slows down password guessing against /login. Failed
+// logins are counted per email address and per client IP; past a few free
+// attempts each failure doubles the wait before the next one, and enough
+// failures for one address lock the account until it times out or an admin
+// unlocks it.
+//
+// Addresses without an account are counted and throttled exactly like
+// existing ones, and CheckPassword spends the same bcrypt time on both, so
+// neither the answers nor their timing reveal which addresses are
+// registered.
+package loginguard
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/config"
+	model "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// dummyHash is compared against when no user has the address, so a login
+// for an unknown address takes as long as one with a wrong password. It uses
+// the cost of the real password hashes.
+var dummyHash = sync.OnceValue(func() []byte {
+	hash, err := bcrypt.GenerateFromPassword([]byte("not the password of any user"), bcrypt.DefaultCost)
+	if err != nil {
+		panic(err)
+	}
+	return hash
+})
+
+// CheckPassword reports whether password is the user's. A nil user, meaning
+// no account has the address, never matches but costs the same bcrypt
+// comparison.
+func CheckPassword(user *model.User, password string) bool {
+	if user == nil {
+		_ = bcrypt.CompareHashAndPassword(dummyHash(), []byte(password))
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) == nil
+}
+
+// LockedFor returns how much longer the user's account is locked, or zero.
+func LockedFor(user model.User, now time.Time) time.Duration {
+	if user.LockedUntil == nil || !user.LockedUntil.After(now) {
+		return 0
+	}
+	return user.LockedUntil.Sub(now)
+}
+
+// Guard applies a config.Login policy.
+type Guard struct {
+	attempts repository.LoginAttemptRepository
+	users    repository.UserRepository
+	policy   config.Login
+}
+
+func New(attempts repository.LoginAttemptRepository, users repository.UserRepository, policy config.Login) *Guard {
+	return &Guard{attempts: attempts, users: users, policy: policy}
+}
+
+func emailKey(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+// delay is how long to wait after the last of failures before trying again.
+func (g *Guard) delay(failures, free int, lockout bool) time.Duration {
+	if lockout && failures >= g.policy.LockoutThreshold {
+		return g.policy.LockoutDuration
+	}
+	if failures < free {
+		return 0
+	}
+	d := g.policy.BackoffBase
+	for i := free; i < failures && d < g.policy.BackoffMax; i++ {
+		d *= 2
+	}
+	return min(d, g.policy.BackoffMax)
+}
+
+// wait is how much of the delay for one counter is left at now.
+func (g *Guard) wait(ctx context.Context, key string, free int, lockout bool, now time.Time) (time.Duration, error) {
+	attempt, err := g.attempts.Get(ctx, key)
+	if errors.Is(err, repository.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return max(attempt.LastFailureAt.Add(g.delay(attempt.Failures, free, lockout)).Sub(now), 0), nil
+}
+
+// Wait returns how long the client at ip has to wait before it may try to
+// log in as email, or zero when it may try now.
+func (g *Guard) Wait(ctx context.Context, email, ip string, now time.Time) (time.Duration, error) {
+	byEmail, err := g.wait(ctx, emailKey(email), g.policy.FreeAttempts, true, now)
+	if err != nil {
+		return 0, err
+	}
+	byIP, err := g.wait(ctx, ipKey(ip), g.policy.IPFreeAttempts, false, now)
+	if err != nil {
+		return 0, err
+	}
+	return max(byEmail, byIP), nil
+}
+
+// Failed counts a failed login as email from ip. user is the account with
+// the address, or nil when there is none; it is locked once the address
+// reaches the lockout threshold.
+func (g *Guard) Failed(ctx context.Context, email, ip string, user *model.User, now time.Time) error {
+	forgetBefore := now.Add(-g.policy.FailureWindow)
+	expiresAt := now.Add(g.policy.FailureWindow)
+
+	attempt, err := g.attempts.RecordFailure(ctx, emailKey(email), now, forgetBefore, expiresAt)
+	if err != nil {
+		return err
+	}
+	if _, err := g.attempts.RecordFailure(ctx, ipKey(ip), now, forgetBefore, expiresAt); err != nil {
+		return err
+	}
+
+	if user == nil || attempt.Failures < g.policy.LockoutThreshold || LockedFor(*user, now) > 0 {
+		return nil
+	}
+	if err := g.users.Lock(ctx, user.UserID, now.Add(g.policy.LockoutDuration), now); err != nil {
+		return err
+	}
+	log.Printf("User %s locked for %s after %d failed logins", user.UserID, g.policy.LockoutDuration, attempt.Failures)
+	return nil
+}
+
+// Succeeded forgets the failures for email. The IP counter is left to
+// expire, so one known password cannot be used to keep guessing others from
+// the same address.
+func (g *Guard) Succeeded(ctx context.Context, email string) error {
+	return g.attempts.Reset(ctx, emailKey(email))
+}
+
+// Unlock lifts a user's lockout and forgets the failures for their address.
+func (g *Guard) Unlock(ctx context.Context, userID string, now time.Time) (model.User, error) {
+	user, err := g.users.Unlock(ctx, userID, now)
+	if err != nil {
+		return model.User{}, err
+	}
+	if err := g.attempts.Reset(ctx, emailKey(user.Email)); err != nil {
+		return model.User{}, err
+	}
+	return user, nil
+}
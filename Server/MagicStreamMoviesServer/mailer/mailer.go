@@ -0,0 +1,61 @@
+// Package mailer sends the account emails: verification links and password
+// resets. SMTP is used in production; the outbox implementation keeps the
+// messages local for development and tests.
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/config"
+)
+
+// Message is a plain text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers messages.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New returns the mailer selected by cfg.Backend. config.Validate has
+// already checked the backend and its settings.
+func New(cfg config.Mail) Mailer {
+	switch cfg.Backend {
+	case "smtp":
+		return NewSMTPMailer(cfg)
+	case "file":
+		return NewOutboxMailer(cfg.From, cfg.OutboxDir)
+	default:
+		return NewOutboxMailer(cfg.From, "")
+	}
+}
+
+// format renders msg as an RFC 5322 message.
+func format(from string, msg Message, at time.Time) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", at.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// validHeader rejects values that could smuggle extra headers into a
+// message.
+func validHeader(value string) error {
+	if strings.ContainsAny(value, "\r\n") {
+		return fmt.Errorf("mail header %q contains a line break", value)
+	}
+	return nil
+}
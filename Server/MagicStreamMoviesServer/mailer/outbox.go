@@ -0,0 +1,50 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OutboxMailer keeps mail on the local machine. With a directory each message
+// is written there as an .eml file, which mail clients open and tests read
+// back; without one it is written to the log.
+type OutboxMailer struct {
+	from string
+	dir  string
+}
+
+func NewOutboxMailer(from, dir string) *OutboxMailer {
+	return &OutboxMailer{from: from, dir: dir}
+}
+
+func (m *OutboxMailer) Send(ctx context.Context, msg Message) error {
+	for _, header := range []string{msg.To, msg.Subject} {
+		if err := validHeader(header); err != nil {
+			return err
+		}
+	}
+	now := time.Now()
+	data := format(m.from, msg, now)
+
+	if m.dir == "" {
+		log.Printf("Mail to %s:\n%s", msg.To, data)
+		return nil
+	}
+
+	if err := os.MkdirAll(m.dir, 0o700); err != nil {
+		return fmt.Errorf("creating outbox: %w", err)
+	}
+	// The timestamp keeps the files in sending order; the ObjectID keeps
+	// names unique within a second.
+	name := fmt.Sprintf("%s-%s.eml", now.UTC().Format("20060102T150405Z"), primitive.NewObjectID().Hex())
+	if err := os.WriteFile(filepath.Join(m.dir, name), data, 0o600); err != nil {
+		return fmt.Errorf("writing %s to outbox: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/config"
+)
+
+// SMTPMailer sends mail through an SMTP server, authenticating with PLAIN
+// when a username is configured. net/smtp upgrades the connection with
+// STARTTLS when the server offers it.
+type SMTPMailer struct {
+	from     string
+	addr     string
+	host     string
+	username string
+	password string
+}
+
+func NewSMTPMailer(cfg config.Mail) *SMTPMailer {
+	return &SMTPMailer{
+		from:     cfg.From,
+		addr:     net.JoinHostPort(cfg.SMTPHost, strconv.Itoa(cfg.SMTPPort)),
+		host:     cfg.SMTPHost,
+		username: cfg.SMTPUsername,
+		password: cfg.SMTPPassword,
+	}
+}
+
+// Send delivers msg. smtp.SendMail does not take a context, so it runs in a
+// goroutine and Send gives up waiting when ctx is done.
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	for _, header := range []string{msg.To, msg.Subject} {
+		if err := validHeader(header); err != nil {
+			return err
+		}
+	}
+	from, err := mail.ParseAddress(m.from)
+	if err != nil {
+		return fmt.Errorf("invalid from address: %w", err)
+	}
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient: %w", err)
+	}
+
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(m.addr, auth, from.Address, []string{to.Address}, format(m.from, msg, time.Now()))
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("sending mail to %s: %w", to.Address, err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -2,82 +2,165 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
-	"strings"
+	"os/signal"
+	"syscall"
 	"time"
 
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
 
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/config"
 	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/database"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/fixtures"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/health"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/jwks"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/migrations"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/repository"
 	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/routes"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/utils"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
 func main() {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	configFile := flag.String("config", "", "YAML or TOML config file; defaults to $CONFIG_FILE")
+	storage := flag.String("storage", "", "where data is kept: mongo or memory; overrides the configured storage")
+	fixtureDir := flag.String("fixtures", "", "with --storage=memory, load the seed JSON files in this directory on start")
+	flag.Parse()
 
-	err := godotenv.Load(".env")
+	cfg, err := config.Load(*configFile)
 	if err != nil {
-		log.Println("Warning: unable to find .env file")
+		log.Fatal(err)
+	}
+	if *storage != "" {
+		cfg.Storage = *storage
+	}
+	if cfg.IsProduction() {
+		gin.SetMode(gin.ReleaseMode)
 	}
 
-	client := database.Connect()
-	defer func() {
-		_ = client.Disconnect(context.Background())
-	}()
-
-	if err := client.Ping(ctx, nil); err != nil {
-		fmt.Println("Failed to ping MongoDB:", err)
-		return
+	keys, err := jwks.Load(jwks.Options{
+		Dir:        cfg.Auth.KeyDir,
+		Algorithm:  cfg.Auth.SigningAlgorithm,
+		SigningKey: cfg.Auth.SigningKeyID,
+		Retiring:   cfg.Auth.RetiringKeyIDs,
+		Generate:   cfg.Auth.GenerateKeys,
+	})
+	if err != nil {
+		log.Fatalf("Failed to load signing keys: %v", err)
 	}
+	log.Printf("Signing tokens with %s key %s", keys.SigningKey().Algorithm, keys.SigningKey().ID)
+	tokens := utils.NewTokens(keys, cfg.Auth)
 
-	router := gin.Default()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
-	// CORS Configuration
-	allowedOrigins := os.Getenv("ALLOWED_ORIGINS")
-	var origins []string
+	probe := &health.Probe{}
 
-	if allowedOrigins != "" {
-		origins = strings.Split(allowedOrigins, ",")
-		for i := range origins {
-			origins[i] = strings.TrimSpace(origins[i])
-			log.Println("Allowed Origin:", origins[i])
+	var store *repository.Store
+	switch cfg.Storage {
+	case "mongo":
+		client := database.Connect(cfg.Mongo.URI)
+		defer func() {
+			_ = client.Disconnect(context.Background())
+		}()
+
+		if err := client.Ping(ctx, nil); err != nil {
+			fmt.Println("Failed to ping MongoDB:", err)
+			return
 		}
-	} else {
-		origins = []string{
-			"http://localhost:5173", // Vite
-			"http://localhost:5174",
-			"http://localhost:3000", // React
-			"http://localhost:8081",
+
+		db := client.Database(cfg.Mongo.Database)
+		if cfg.Mongo.Migrate {
+			migrateCtx, cancelMigrate := context.WithTimeout(context.Background(), 5*time.Minute)
+			applied, err := migrations.Up(migrateCtx, db)
+			cancelMigrate()
+			for _, migration := range applied {
+				log.Printf("Applied migration %d %s", migration.Version, migration.Name)
+			}
+			if err != nil {
+				log.Fatalf("Failed to migrate the database: %v", err)
+			}
+		} else if pending, err := migrations.Pending(ctx, db); err != nil {
+			log.Println("Warning: unable to check for schema migrations:", err)
+		} else if len(pending) > 0 {
+			log.Printf("Warning: %d schema migrations are pending; run cmd/migrate up", len(pending))
 		}
-		log.Println("Using default allowed origins for development")
-	}
 
-	corsConfig := cors.Config{
-		AllowOrigins:     origins,
-		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
+		store = repository.NewMongoStore(db)
+		if !cfg.RateLimit.Shared {
+			store.RateLimits = repository.NewMemoryRateLimits()
+		}
+		probe.Register("mongo", func(ctx context.Context) error {
+			return client.Ping(ctx, readpref.Primary())
+		})
+	case "memory":
+		var seed fixtures.Set
+		if *fixtureDir != "" {
+			seed, err = fixtures.Load(*fixtureDir)
+			if err != nil {
+				log.Fatalf("Failed to load fixtures: %v", err)
+			}
+		}
+		store = repository.NewMemoryStore(seed)
+		log.Println("Using in-memory storage; all data is lost when the server stops")
+	default:
+		log.Fatalf("Unknown storage %q, expected mongo or memory", cfg.Storage)
 	}
 
-	router.Use(cors.New(corsConfig))
-	router.Use(gin.Logger())
+	router, err := routes.NewRouter(cfg)
+	if err != nil {
+		log.Fatalf("Failed to set up the router: %v", err)
+	}
 
 	router.GET("/hello", func(c *gin.Context) {
 		c.String(200, "Hello, MagicStreamMoviesServer!")
 	})
 
-	routes.SetupProtectedRoutes(router, client)
-	routes.SetupUnProtectedRoutes(router, client)
+	routes.SetupHealthRoutes(router, probe)
+	routes.SetupProtectedRoutes(router, store, cfg, tokens)
+	routes.SetupUnProtectedRoutes(router, store, cfg, tokens)
 
-	fmt.Println("Server starting on :8080")
-	if err := router.Run(":8080"); err != nil {
+	server := &http.Server{
+		Addr:         cfg.Server.Addr(),
+		Handler:      router,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		fmt.Println("Server starting on", cfg.Server.Addr())
+		serveErr <- server.ListenAndServe()
+	}()
+
+	stop, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	select {
+	case err := <-serveErr:
 		fmt.Println("Failed to start server:", err)
+		return
+	case <-stop.Done():
+	}
+	stopSignals()
+
+	// Fail readiness first and keep serving for the drain delay so load
+	// balancers stop sending traffic, then let in-flight requests finish. A
+	// second signal kills the process. The deferred Disconnect closes Mongo
+	// once main returns.
+	log.Println("Shutting down, draining in-flight requests")
+	probe.Drain()
+	time.Sleep(cfg.Server.DrainDelay)
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancelShutdown()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Println("Warning: forced shutdown before all requests finished:", err)
 	}
+	log.Println("Server stopped")
 }
@@ -1,13 +1,18 @@
 package middleware
 
 import (
+	"log"
 	"net/http"
+	"time"
 
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/repository"
 	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/utils"
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleWare() gin.HandlerFunc {
+// AuthMiddleWare accepts requests carrying a valid access token that has not
+// been revoked, and stores its claims on the context.
+func AuthMiddleWare(tokens *utils.Tokens, revocations repository.RevocationRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		token, err := utils.GetAccessToken(c)
 
@@ -21,15 +26,35 @@ func AuthMiddleWare() gin.HandlerFunc {
 			c.Abort()
 			return
 		}
-		claims, err := utils.ValidateToken(token)
+		claims, err := tokens.ValidateToken(token)
 
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			c.Abort()
 			return
 		}
+
+		var issuedAt time.Time
+		if claims.IssuedAt != nil {
+			issuedAt = claims.IssuedAt.Time
+		}
+		revoked, err := revocations.IsRevoked(c.Request.Context(), claims.ID, claims.SessionID, claims.UserID, issuedAt)
+		if err != nil {
+			log.Println("Error: unable to verify token:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to verify token"})
+			c.Abort()
+			return
+		}
+		if revoked {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
+			c.Abort()
+			return
+		}
+
 		c.Set("userId", claims.UserID)
 		c.Set("role", claims.Role)
+		c.Set("sessionId", claims.SessionID)
+		c.Set("tokenId", claims.ID)
 
 		c.Next()
 
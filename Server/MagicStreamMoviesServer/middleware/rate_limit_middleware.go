@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/config"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/repository"
+)
+
+// RateLimit throttles each client to policy with a token bucket from
+// buckets. Clients are keyed by the user ID AuthMiddleWare stores, so it has
+// to come after AuthMiddleWare on protected routes, and by client IP
+// otherwise. name keeps the buckets of different policies apart.
+//
+// Every response carries the RateLimit-Limit, RateLimit-Remaining,
+// RateLimit-Reset and RateLimit-Policy headers of the IETF draft; a refused
+// request gets 429 with Retry-After. If the buckets cannot be reached the
+// request is let through rather than taking the API down with the store.
+func RateLimit(buckets repository.RateLimitRepository, name string, policy config.RateLimitPolicy) gin.HandlerFunc {
+	rate := float64(policy.Limit) / policy.Window.Seconds()
+	policyHeader := strconv.Itoa(policy.Limit) + ";w=" + strconv.Itoa(int(math.Ceil(policy.Window.Seconds())))
+
+	return func(c *gin.Context) {
+		client := "ip:" + c.ClientIP()
+		if userID := c.GetString("userId"); userID != "" {
+			client = "user:" + userID
+		}
+
+		now := time.Now()
+		bucket, err := buckets.Take(c.Request.Context(), name+":"+client, policy.Limit, rate, now)
+		if err != nil {
+			log.Printf("Warning: rate limit %s unavailable, letting request through: %v", name, err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(policy.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(int(bucket.Tokens)))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(bucket.ExpiresAt.Sub(now))))
+		c.Header("RateLimit-Policy", policyHeader)
+
+		if !bucket.Allowed {
+			retryAfter := ceilSeconds(time.Duration((1 - bucket.Tokens) / rate * float64(time.Second)))
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests, slow down", "retry_after": retryAfter})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// ceilSeconds rounds d up to whole seconds, as the headers need.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
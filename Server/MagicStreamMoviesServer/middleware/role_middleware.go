@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Roles a user can hold, as stored in models.User.Role and carried in the
+// access token.
+const (
+	RoleAdmin = "ADMIN"
+	RoleUser  = "USER"
+)
+
+// Permission names an action that can be granted to a role.
+type Permission string
+
+const (
+	PermMovieRead    Permission = "movie:read"
+	PermMovieCreate  Permission = "movie:create"
+	PermMovieUpdate  Permission = "movie:update"
+	PermMovieDelete  Permission = "movie:delete"
+	PermReviewUpdate Permission = "review:update"
+	PermRatingWrite  Permission = "rating:write"
+	PermProfileRead  Permission = "profile:read"
+	PermProfileWrite Permission = "profile:write"
+	PermUserManage   Permission = "user:manage"
+	PermGenreManage  Permission = "genre:manage"
+)
+
+// rolePermissions is the permission matrix. Every protected route declares
+// the permission it needs at registration, and a role may only reach the
+// route if the permission is listed for it here.
+var rolePermissions = map[string][]Permission{
+	RoleAdmin: {
+		PermMovieRead, PermMovieCreate, PermMovieUpdate, PermMovieDelete,
+		PermReviewUpdate, PermRatingWrite, PermProfileRead, PermProfileWrite,
+		PermUserManage, PermGenreManage,
+	},
+	RoleUser: {
+		PermMovieRead, PermRatingWrite, PermProfileRead, PermProfileWrite,
+	},
+}
+
+// HasPermission reports whether role is granted permission by the matrix.
+func HasPermission(role string, permission Permission) bool {
+	for _, granted := range rolePermissions[role] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// forbidden writes the 403 body shared by every authorization check.
+func forbidden(c *gin.Context, required string) {
+	c.JSON(http.StatusForbidden, gin.H{
+		"error":    "Access denied",
+		"required": required,
+	})
+	c.Abort()
+}
+
+// RequirePermission allows the request through only if the role set by
+// AuthMiddleWare holds every listed permission. It must be registered after
+// AuthMiddleWare.
+func RequirePermission(permissions ...Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+		for _, permission := range permissions {
+			if !HasPermission(role, permission) {
+				forbidden(c, string(permission))
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// RequireRole allows the request through only if the role set by
+// AuthMiddleWare is one of roles. Prefer RequirePermission for new routes.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+		forbidden(c, strings.Join(roles, " or "))
+	}
+}
@@ -0,0 +1,309 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// all is every migration in version order. Add new ones at the end and never
+// change the version of one that has shipped.
+var all = []Migration{
+	{
+		Version: 1,
+		Name:    "unique_user_email_and_id",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			users := db.Collection("users")
+			if err := ensureUniqueIndex(ctx, users, "email"); err != nil {
+				return err
+			}
+			return ensureUniqueIndex(ctx, users, "user_id")
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndexes(ctx, db.Collection("users"), "email_unique", "user_id_unique")
+		},
+	},
+	{
+		Version: 2,
+		Name:    "unique_movie_imdb_id",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return ensureUniqueIndex(ctx, db.Collection("movies"), "imdb_id")
+		},
+		// Deployed databases had a plain imdb_id index before this one, and
+		// lookups by imdb_id still need it
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			movies := db.Collection("movies")
+			if err := dropIndexes(ctx, movies, "imdb_id_unique"); err != nil {
+				return err
+			}
+			_, err := movies.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "imdb_id", Value: 1}}})
+			return err
+		},
+	},
+	{
+		Version: 3,
+		Name:    "unique_genre_id",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return ensureUniqueIndex(ctx, db.Collection("genres"), "genre_id")
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndexes(ctx, db.Collection("genres"), "genre_id_unique")
+		},
+	},
+	{
+		Version: 4,
+		Name:    "default_genres",
+		// Replaces the list GET /genres used to fall back to, so a new
+		// database starts with genres admins can edit
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			genres := db.Collection("genres")
+			count, err := genres.CountDocuments(ctx, bson.M{})
+			if err != nil || count > 0 {
+				return err
+			}
+			defaults := bson.A{}
+			for i, name := range []string{"Comedy", "Drama", "Western", "Fantasy", "Thriller", "Sci-Fi", "Action", "Mystery", "Crime"} {
+				defaults = append(defaults, bson.M{"genre_id": i + 1, "genre_name": name})
+			}
+			_, err = genres.InsertMany(ctx, defaults, options.InsertMany().SetOrdered(false))
+			if mongo.IsDuplicateKeyError(err) {
+				// Another server inserted them at the same time
+				return nil
+			}
+			return err
+		},
+		// The genres may have been edited or be in use by now, so they stay
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return nil
+		},
+	},
+	{
+		Version: 5,
+		Name:    "movie_catalogue_indexes",
+		// Back each sort order of the paginated catalogue with _id as the
+		// keyset tie-breaker. The genre index was already in deployed
+		// databases, so it is created here but left alone by Down.
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return createIndexes(ctx, db.Collection("movies"),
+				bson.D{{Key: "genre.genre_id", Value: 1}},
+				bson.D{{Key: "title", Value: 1}, {Key: "_id", Value: 1}},
+				bson.D{{Key: "ranking.ranking_value", Value: 1}, {Key: "_id", Value: 1}},
+			)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndexKeys(ctx, db.Collection("movies"),
+				bson.D{{Key: "title", Value: 1}, {Key: "_id", Value: 1}},
+				bson.D{{Key: "ranking.ranking_value", Value: 1}, {Key: "_id", Value: 1}},
+			)
+		},
+	},
+	{
+		Version: 6,
+		Name:    "rating_indexes",
+		// Each user has at most one rating per movie, and imdb_id backs the
+		// per-movie aggregate
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("ratings").Indexes().CreateMany(ctx, []mongo.IndexModel{
+				{Keys: userMovie, Options: options.Index().SetUnique(true)},
+				{Keys: bson.D{{Key: "imdb_id", Value: 1}, {Key: "_id", Value: -1}}},
+			})
+			return err
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndexKeys(ctx, db.Collection("ratings"), userMovie, bson.D{{Key: "imdb_id", Value: 1}, {Key: "_id", Value: -1}})
+		},
+	},
+	{
+		Version: 7,
+		Name:    "watch_indexes",
+		// Watchlist and watch history entries are unique per user and movie,
+		// and listed per user newest first
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("watchlist").Indexes().CreateMany(ctx, []mongo.IndexModel{
+				{Keys: userMovie, Options: options.Index().SetUnique(true)},
+				{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "added_at", Value: -1}}},
+			})
+			if err != nil {
+				return err
+			}
+			_, err = db.Collection("watch_history").Indexes().CreateMany(ctx, []mongo.IndexModel{
+				{Keys: userMovie, Options: options.Index().SetUnique(true)},
+				{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "last_watched_at", Value: -1}}},
+			})
+			return err
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			err := dropIndexKeys(ctx, db.Collection("watchlist"), userMovie, bson.D{{Key: "user_id", Value: 1}, {Key: "added_at", Value: -1}})
+			if err != nil {
+				return err
+			}
+			return dropIndexKeys(ctx, db.Collection("watch_history"), userMovie, bson.D{{Key: "user_id", Value: 1}, {Key: "last_watched_at", Value: -1}})
+		},
+	},
+	{
+		Version: 8,
+		Name:    "session_indexes",
+		// Refresh token hashes are unique, sessions are looked up per user and
+		// family, and MongoDB deletes them once the refresh token expires
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("sessions").Indexes().CreateMany(ctx, []mongo.IndexModel{
+				{Keys: bson.D{{Key: "refresh_token_hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+				{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "family_id", Value: 1}}},
+				expiresAt,
+			})
+			return err
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndexKeys(ctx, db.Collection("sessions"),
+				bson.D{{Key: "refresh_token_hash", Value: 1}},
+				bson.D{{Key: "user_id", Value: 1}, {Key: "family_id", Value: 1}},
+				expiresAt.Keys.(bson.D),
+			)
+		},
+	},
+	{
+		Version: 9,
+		Name:    "revocation_indexes",
+		// Back the three ways a revocation matches a token, and delete entries
+		// once every token they match has expired
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("revocations").Indexes().CreateMany(ctx, []mongo.IndexModel{
+				{Keys: bson.D{{Key: "jti", Value: 1}}, Options: options.Index().SetSparse(true)},
+				{Keys: bson.D{{Key: "session_id", Value: 1}}, Options: options.Index().SetSparse(true)},
+				{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "issued_before", Value: -1}}, Options: options.Index().SetSparse(true)},
+				expiresAt,
+			})
+			return err
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndexKeys(ctx, db.Collection("revocations"),
+				bson.D{{Key: "jti", Value: 1}},
+				bson.D{{Key: "session_id", Value: 1}},
+				bson.D{{Key: "user_id", Value: 1}, {Key: "issued_before", Value: -1}},
+				expiresAt.Keys.(bson.D),
+			)
+		},
+	},
+	{
+		Version: 10,
+		Name:    "user_token_indexes",
+		// Token hashes are unique, a user's outstanding tokens can be
+		// invalidated together, and tokens are deleted once they expire
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("user_tokens").Indexes().CreateMany(ctx, []mongo.IndexModel{
+				{Keys: bson.D{{Key: "token_hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+				{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "purpose", Value: 1}}},
+				expiresAt,
+			})
+			return err
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndexKeys(ctx, db.Collection("user_tokens"),
+				bson.D{{Key: "token_hash", Value: 1}},
+				bson.D{{Key: "user_id", Value: 1}, {Key: "purpose", Value: 1}},
+				expiresAt.Keys.(bson.D),
+			)
+		},
+	},
+	{
+		Version: 11,
+		Name:    "login_attempt_expiry",
+		// Counters are looked up by _id, so they only need deleting once
+		// they are forgotten
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("login_attempts").Indexes().CreateOne(ctx, expiresAt)
+			return err
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndexKeys(ctx, db.Collection("login_attempts"), expiresAt.Keys.(bson.D))
+		},
+	},
+	{
+		Version: 12,
+		Name:    "rate_limit_expiry",
+		// A bucket that has refilled completely is no different from a
+		// missing one
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("rate_limits").Indexes().CreateOne(ctx, expiresAt)
+			return err
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndexKeys(ctx, db.Collection("rate_limits"), expiresAt.Keys.(bson.D))
+		},
+	},
+	{
+		Version: 13,
+		Name:    "audit_indexes",
+		// The audit trail is listed by actor and by target user, newest first
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return createIndexes(ctx, db.Collection("audit_log"),
+				bson.D{{Key: "target_id", Value: 1}, {Key: "at", Value: -1}},
+				bson.D{{Key: "actor_id", Value: 1}, {Key: "at", Value: -1}},
+			)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndexKeys(ctx, db.Collection("audit_log"),
+				bson.D{{Key: "target_id", Value: 1}, {Key: "at", Value: -1}},
+				bson.D{{Key: "actor_id", Value: 1}, {Key: "at", Value: -1}},
+			)
+		},
+	},
+	{
+		Version: 14,
+		Name:    "movie_text_index",
+		// Lets Mongo score searches, weighted like the in-process index the
+		// server otherwise falls back to. A collection holds one text index
+		// at most, so any other is replaced. Language "none" turns off
+		// stemming and stop words, which would drop words from titles in
+		// other languages; matching ignores case and accents either way.
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			movies := db.Collection("movies")
+			specs, err := movies.Indexes().ListSpecifications(ctx)
+			if err != nil {
+				return err
+			}
+			for _, spec := range specs {
+				if spec.Name == "movie_text" {
+					return nil
+				}
+				if _, ok := spec.KeysDocument.Lookup("_fts").StringValueOK(); ok {
+					if err := dropIndexes(ctx, movies, spec.Name); err != nil {
+						return err
+					}
+				}
+			}
+
+			_, err = movies.Indexes().CreateOne(ctx, mongo.IndexModel{
+				Keys: bson.D{
+					{Key: "title", Value: "text"},
+					{Key: "genre.genre_name", Value: "text"},
+					{Key: "admin_review", Value: "text"},
+				},
+				Options: options.Index().
+					SetName("movie_text").
+					SetWeights(bson.D{
+						{Key: "title", Value: 3},
+						{Key: "genre.genre_name", Value: 2},
+						{Key: "admin_review", Value: 1},
+					}).
+					SetDefaultLanguage("none"),
+			})
+			return err
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndexes(ctx, db.Collection("movies"), "movie_text")
+		},
+	},
+}
+
+// userMovie is the key of the collections holding one entry per user and
+// movie.
+var userMovie = bson.D{{Key: "user_id", Value: 1}, {Key: "imdb_id", Value: 1}}
+
+// expiresAt lets MongoDB delete documents once their expires_at has passed.
+var expiresAt = mongo.IndexModel{
+	Keys:    bson.D{{Key: "expires_at", Value: 1}},
+	Options: options.Index().SetExpireAfterSeconds(0),
+}
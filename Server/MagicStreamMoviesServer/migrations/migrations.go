@@ -0,0 +1,293 @@
+// Package migrations applies versioned changes to the MongoDB schema, such as
+// indexes and data rewrites, and records each applied version in the
+// schema_migrations collection so it only runs once.
+//
+// Several servers may start at the same time, so every migration must be
+// safe to run twice.
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Collection records the applied migrations.
+const Collection = "schema_migrations"
+
+// Migration is one schema change. Down may be nil when the change cannot be
+// undone.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context, db *mongo.Database) error
+	Down    func(ctx context.Context, db *mongo.Database) error
+}
+
+// Record is a schema_migrations document.
+type Record struct {
+	Version   int       `bson:"_id"`
+	Name      string    `bson:"name"`
+	AppliedAt time.Time `bson:"applied_at"`
+}
+
+// State is a known migration and when it was applied, nil while pending.
+// Versions recorded by a newer build have an empty Name and no Up.
+type State struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+func init() {
+	for i := 1; i < len(all); i++ {
+		if all[i].Version <= all[i-1].Version {
+			panic(fmt.Sprintf("migrations: version %d is listed after %d", all[i].Version, all[i-1].Version))
+		}
+	}
+}
+
+func applied(ctx context.Context, db *mongo.Database) (map[int]Record, error) {
+	cursor, err := db.Collection(Collection).Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	var records []Record
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]Record, len(records))
+	for _, record := range records {
+		byVersion[record.Version] = record
+	}
+	return byVersion, nil
+}
+
+// Status lists every migration, known or recorded, oldest first.
+func Status(ctx context.Context, db *mongo.Database) ([]State, error) {
+	records, err := applied(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]State, 0, len(all))
+	for _, migration := range all {
+		state := State{Migration: migration}
+		if record, ok := records[migration.Version]; ok {
+			state.AppliedAt = &record.AppliedAt
+			delete(records, migration.Version)
+		}
+		states = append(states, state)
+	}
+	for _, record := range records {
+		at := record.AppliedAt
+		states = append(states, State{Migration: Migration{Version: record.Version, Name: record.Name}, AppliedAt: &at})
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Version < states[j].Version })
+	return states, nil
+}
+
+// Pending returns the migrations Up would apply.
+func Pending(ctx context.Context, db *mongo.Database) ([]Migration, error) {
+	records, err := applied(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, migration := range all {
+		if _, ok := records[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Up applies the pending migrations oldest first and returns those it
+// applied. It stops at the first failure, leaving the later ones pending.
+func Up(ctx context.Context, db *mongo.Database) ([]Migration, error) {
+	pending, err := Pending(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range pending {
+		if err := migration.Up(ctx, db); err != nil {
+			return done, fmt.Errorf("migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+		record := Record{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}
+		_, err := db.Collection(Collection).InsertOne(ctx, record)
+		if err != nil && !mongo.IsDuplicateKeyError(err) {
+			// A duplicate means another server applied it at the same time
+			return done, fmt.Errorf("record migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Down reverts the newest steps applied migrations, newest first, and
+// returns those it reverted.
+func Down(ctx context.Context, db *mongo.Database, steps int) ([]Migration, error) {
+	states, err := Status(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for i := len(states) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := states[i].Migration
+		if states[i].AppliedAt == nil {
+			continue
+		}
+		if migration.Up == nil {
+			return done, fmt.Errorf("migration %d %s was applied by a newer build and is unknown to this one", migration.Version, migration.Name)
+		}
+		if migration.Down == nil {
+			return done, fmt.Errorf("migration %d %s cannot be reverted", migration.Version, migration.Name)
+		}
+		if err := migration.Down(ctx, db); err != nil {
+			return done, fmt.Errorf("revert migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+		if _, err := db.Collection(Collection).DeleteOne(ctx, bson.M{"_id": migration.Version}); err != nil {
+			return done, fmt.Errorf("unrecord migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// uniqueIndex returns a unique index on one field, named so that Down can
+// drop it.
+func uniqueIndex(field string) mongo.IndexModel {
+	return mongo.IndexModel{
+		Keys:    bson.D{{Key: field, Value: 1}},
+		Options: options.Index().SetUnique(true).SetName(field + "_unique"),
+	}
+}
+
+// dropIndexes drops the named indexes, ignoring those already gone.
+func dropIndexes(ctx context.Context, collection *mongo.Collection, names ...string) error {
+	for _, name := range names {
+		_, err := collection.Indexes().DropOne(ctx, name)
+		var commandErr mongo.CommandError
+		if errors.As(err, &commandErr) && (commandErr.Code == 27 || commandErr.Code == 26) {
+			// IndexNotFound, or NamespaceNotFound for a missing collection
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureUniqueIndex adds uniqueIndex(field) to a collection that may already
+// hold data and an index on field. It first reports values held by more than
+// one document, which would make the build fail, and replaces an existing
+// non-unique index on field, which would conflict with the new one. An
+// existing unique index on field is kept whatever its name.
+func ensureUniqueIndex(ctx context.Context, collection *mongo.Collection, field string) error {
+	duplicates, err := duplicateValues(ctx, collection, field)
+	if err != nil {
+		return err
+	}
+	if len(duplicates) > 0 {
+		return fmt.Errorf("%s.%s has duplicate values %v; remove the duplicates and run cmd/migrate up", collection.Name(), field, duplicates)
+	}
+
+	specs, err := collection.Indexes().ListSpecifications(ctx)
+	if err != nil {
+		return err
+	}
+	for _, spec := range specs {
+		if !onField(spec.KeysDocument, field) {
+			continue
+		}
+		if spec.Unique != nil && *spec.Unique {
+			return nil
+		}
+		if err := dropIndexes(ctx, collection, spec.Name); err != nil {
+			return err
+		}
+	}
+
+	_, err = collection.Indexes().CreateOne(ctx, uniqueIndex(field))
+	return err
+}
+
+// duplicateValues returns up to ten values of field held by more than one
+// document.
+func duplicateValues(ctx context.Context, collection *mongo.Collection, field string) ([]interface{}, error) {
+	cursor, err := collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$group", Value: bson.D{{Key: "_id", Value: "$" + field}, {Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}}}}},
+		{{Key: "$match", Value: bson.D{{Key: "count", Value: bson.D{{Key: "$gt", Value: 1}}}}}},
+		{{Key: "$limit", Value: 10}},
+	}, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return nil, err
+	}
+	var groups []struct {
+		Value interface{} `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+
+	values := make([]interface{}, 0, len(groups))
+	for _, group := range groups {
+		values = append(values, group.Value)
+	}
+	return values, nil
+}
+
+// onField reports whether an index key document is an ascending index on
+// field alone.
+func onField(keys bson.Raw, field string) bool {
+	elements, err := keys.Elements()
+	if err != nil || len(elements) != 1 || elements[0].Key() != field {
+		return false
+	}
+	// The direction may be stored as an int32, int64 or double
+	direction, ok := elements[0].Value().AsInt64OK()
+	return ok && direction == 1
+}
+
+// createIndexes creates plain indexes with the given keys.
+func createIndexes(ctx context.Context, collection *mongo.Collection, keys ...bson.D) error {
+	models := make([]mongo.IndexModel, 0, len(keys))
+	for _, key := range keys {
+		models = append(models, mongo.IndexModel{Keys: key})
+	}
+	_, err := collection.Indexes().CreateMany(ctx, models)
+	return err
+}
+
+// dropIndexKeys drops the indexes created without a name for the given keys.
+// The indexes created at startup before migrations existed were named this
+// way too, so Down removes those as well.
+func dropIndexKeys(ctx context.Context, collection *mongo.Collection, keys ...bson.D) error {
+	names := make([]string, 0, len(keys))
+	for _, key := range keys {
+		names = append(names, defaultIndexName(key))
+	}
+	return dropIndexes(ctx, collection, names...)
+}
+
+// defaultIndexName is the name MongoDB gives an index created without one,
+// such as "title_1__id_1".
+func defaultIndexName(keys bson.D) string {
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s_%v", key.Key, key.Value))
+	}
+	return strings.Join(parts, "_")
+}
@@ -0,0 +1,48 @@
+package migrations
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestOnField(t *testing.T) {
+	tests := []struct {
+		name string
+		keys bson.D
+		want bool
+	}{
+		{"int32", bson.D{{Key: "imdb_id", Value: int32(1)}}, true},
+		{"int64", bson.D{{Key: "imdb_id", Value: int64(1)}}, true},
+		{"double", bson.D{{Key: "imdb_id", Value: 1.0}}, true},
+		{"descending", bson.D{{Key: "imdb_id", Value: -1}}, false},
+		{"text", bson.D{{Key: "imdb_id", Value: "text"}}, false},
+		{"other field", bson.D{{Key: "title", Value: 1}}, false},
+		{"compound", bson.D{{Key: "imdb_id", Value: 1}, {Key: "_id", Value: 1}}, false},
+	}
+	for _, test := range tests {
+		keys, err := bson.Marshal(test.keys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := onField(keys, "imdb_id"); got != test.want {
+			t.Errorf("%s: onField = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestDefaultIndexName(t *testing.T) {
+	tests := []struct {
+		keys bson.D
+		want string
+	}{
+		{bson.D{{Key: "expires_at", Value: 1}}, "expires_at_1"},
+		{bson.D{{Key: "title", Value: 1}, {Key: "_id", Value: 1}}, "title_1__id_1"},
+		{bson.D{{Key: "imdb_id", Value: 1}, {Key: "_id", Value: -1}}, "imdb_id_1__id_-1"},
+	}
+	for _, test := range tests {
+		if got := defaultIndexName(test.keys); got != test.want {
+			t.Errorf("defaultIndexName(%v) = %q, want %q", test.keys, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Audited admin actions.
+const (
+	AuditUserRoleChanged   = "user.role_changed"
+	AuditUserStatusChanged = "user.status_changed"
+	AuditUserDeleted       = "user.deleted"
+	AuditUserUnlocked      = "user.unlocked"
+)
+
+// AuditEntry records one admin action: who did what to which user, and
+// the values involved.
+type AuditEntry struct {
+	ID       primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ActorID  string             `bson:"actor_id" json:"actor_id"`
+	Action   string             `bson:"action" json:"action"`
+	TargetID string             `bson:"target_id" json:"target_id"`
+	Details  map[string]string  `bson:"details,omitempty" json:"details,omitempty"`
+	At       time.Time          `bson:"at" json:"at"`
+}
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// LoginAttempt counts the failed logins for one email address or client IP.
+// Key is "email:" or "ip:" followed by the address.
+type LoginAttempt struct {
+	Key           string    `bson:"_id" json:"-"`
+	Failures      int       `bson:"failures" json:"-"`
+	LastFailureAt time.Time `bson:"last_failure_at" json:"-"`
+	// ExpiresAt is when the counter is forgotten; a TTL index removes it then.
+	ExpiresAt time.Time `bson:"expires_at" json:"-"`
+}
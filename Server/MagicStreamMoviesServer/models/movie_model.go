@@ -1,6 +1,8 @@
 package model
 
 import (
+	"time"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -9,6 +11,18 @@ type Genre struct {
 	GenreName string `bson:"genre_name" json:"genre_name" validate:"required,min=2,max=100"`
 }
 
+// GenreCreate is a POST /admin/genres body. Without a genre_id the genre
+// gets the next unused ID.
+type GenreCreate struct {
+	GenreID   int    `json:"genre_id" validate:"omitempty,gt=0"`
+	GenreName string `json:"genre_name" validate:"required,min=2,max=100"`
+}
+
+// GenreRename is a PUT /admin/genres/:genre_id body.
+type GenreRename struct {
+	GenreName string `json:"genre_name" validate:"required,min=2,max=100"`
+}
+
 type Ranking struct {
 	RankingValue int    `bson:"ranking_value" json:"ranking_value" validate:"required"`
 	RankingName  string `bson:"ranking_name" json:"ranking_name" validate:"required"`
@@ -23,4 +37,27 @@ type Movie struct {
 	Genre       []Genre            `bson:"genre" json:"genre" validate:"required,dive"`
 	AdminReview string             `bson:"admin_review" json:"admin_review"`
 	Ranking     Ranking            `bson:"ranking" json:"ranking" validate:"required"`
+	// AudienceScore and RatingCount are denormalised from the ratings
+	// collection and recomputed whenever a rating changes.
+	AudienceScore float64 `bson:"audience_score" json:"audience_score"`
+	RatingCount   int     `bson:"rating_count" json:"rating_count"`
+	// DeletedAt is set when an admin soft deletes the movie. Deleted movies
+	// are hidden everywhere until restored.
+	DeletedAt *time.Time `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+}
+
+// MovieSearchResult is a movie matched by a full-text search together with
+// its relevance score and highlighted snippets keyed by field name.
+type MovieSearchResult struct {
+	Movie      `bson:",inline"`
+	Score      float64           `bson:"score" json:"score"`
+	Highlights map[string]string `bson:"-" json:"highlights"`
+}
+
+// RecommendedMovie is a movie suggested to a user with the score it was given
+// and the reasons behind it.
+type RecommendedMovie struct {
+	Movie   `bson:",inline"`
+	Score   float64  `bson:"score" json:"score"`
+	Reasons []string `bson:"reasons" json:"reasons"`
 }
@@ -0,0 +1,9 @@
+package model
+
+// Page is the envelope returned by every paginated listing. NextCursor is
+// empty when there are no further results.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor"`
+	Total      int64  `json:"total"`
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// RateLimitBucket is the token bucket of one client under one rate limit
+// policy.
+type RateLimitBucket struct {
+	Key       string    `bson:"_id" json:"-"`
+	Tokens    float64   `bson:"tokens" json:"-"`
+	UpdatedAt time.Time `bson:"updated_at" json:"-"`
+	// Allowed reports whether the last Take got a token.
+	Allowed bool `bson:"allowed" json:"-"`
+	// ExpiresAt is when the bucket has refilled completely and can be
+	// forgotten; a TTL index removes it then.
+	ExpiresAt time.Time `bson:"expires_at" json:"-"`
+}
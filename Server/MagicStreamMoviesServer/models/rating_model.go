@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Rating is a user's star rating of a movie, with an optional short review.
+// There is at most one rating per (UserID, ImdbID).
+type Rating struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	UserID    string             `bson:"user_id" json:"user_id"`
+	ImdbID    string             `bson:"imdb_id" json:"imdb_id"`
+	Stars     int                `bson:"stars" json:"stars"`
+	Review    string             `bson:"review,omitempty" json:"review,omitempty"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+type RatingRequest struct {
+	Stars  int    `json:"stars" validate:"required,min=1,max=5"`
+	Review string `json:"review" validate:"max=500"`
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Revocation blocks access tokens before they expire. It matches tokens by
+// jti, by the session they were issued to, or every token of a user issued
+// before IssuedBefore. As issue times only have whole seconds, a user-wide
+// entry also lists the sessions it signed out, which covers their tokens
+// from the second of IssuedBefore itself. An entry is only needed until the
+// last token it matches would have expired anyway, so ExpiresAt is backed by
+// a TTL index.
+type Revocation struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty"`
+	TokenID      string             `bson:"jti,omitempty"`
+	SessionID    string             `bson:"session_id,omitempty"`
+	UserID       string             `bson:"user_id,omitempty"`
+	IssuedBefore *time.Time         `bson:"issued_before,omitempty"`
+	SessionIDs   []string           `bson:"session_ids,omitempty"`
+	Reason       string             `bson:"reason"`
+	RevokedAt    time.Time          `bson:"revoked_at"`
+	ExpiresAt    time.Time          `bson:"expires_at"`
+}
+
+// Revocation reasons beyond the session ones.
+const (
+	RevokedPasswordChanged = "password_changed"
+	RevokedRoleChanged     = "role_changed"
+	RevokedAccountDisabled = "account_disabled"
+	RevokedAccountDeleted  = "account_deleted"
+)
+
+// Matches reports whether the revocation applies to a token with the given
+// claims.
+func (r Revocation) Matches(tokenID, sessionID, userID string, issuedAt time.Time) bool {
+	switch {
+	case r.TokenID != "":
+		return r.TokenID == tokenID
+	case r.SessionID != "":
+		return r.SessionID == sessionID
+	case r.UserID != "" && r.IssuedBefore != nil:
+		return r.UserID == userID && (issuedAt.Before(*r.IssuedBefore) || sessionID != "" && slices.Contains(r.SessionIDs, sessionID))
+	}
+	return false
+}
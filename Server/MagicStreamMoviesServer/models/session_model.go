@@ -0,0 +1,54 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Session is one refresh token issued to a device. Refreshing rotates the
+// token: the session is revoked and replaced by a new one in the same
+// family, so a family is the chain of tokens one login has been through and
+// at most one session per family is active. Only the SHA-256 of the token
+// is stored.
+type Session struct {
+	ID               primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	FamilyID         string             `bson:"family_id" json:"-"`
+	UserID           string             `bson:"user_id" json:"-"`
+	RefreshTokenHash string             `bson:"refresh_token_hash" json:"-"`
+	UserAgent        string             `bson:"user_agent" json:"-"`
+	IP               string             `bson:"ip" json:"-"`
+	CreatedAt        time.Time          `bson:"created_at" json:"-"`
+	// SignedInAt is when the family started, carried over by rotation.
+	SignedInAt time.Time `bson:"signed_in_at" json:"-"`
+	LastUsedAt time.Time `bson:"last_used_at" json:"-"`
+	// ExpiresAt is when the refresh token expires; a TTL index removes the
+	// session then.
+	ExpiresAt     time.Time  `bson:"expires_at" json:"-"`
+	RevokedAt     *time.Time `bson:"revoked_at,omitempty" json:"-"`
+	RevokedReason string     `bson:"revoked_reason,omitempty" json:"-"`
+}
+
+// Session revocation reasons.
+const (
+	SessionRotated   = "rotated"
+	SessionReused    = "reuse_detected"
+	SessionLoggedOut = "logged_out"
+)
+
+// Active reports whether the session's refresh token may still be used.
+func (s Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// SessionResponse describes a signed-in device to its owner. SessionID is
+// the family ID, which stays the same across refreshes.
+type SessionResponse struct {
+	SessionID  string    `json:"session_id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	SignedInAt time.Time `json:"signed_in_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
@@ -5,6 +5,14 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Account statuses. Users stored before statuses existed have none and are
+// active.
+const (
+	UserActive   = "active"
+	UserDisabled = "disabled"
+	UserDeleted  = "deleted"
+)
+
 type User struct {
 	ID              primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
 	UserID          string        `json:"user_id" bson:"user_id"`
@@ -15,14 +23,41 @@ type User struct {
 	Role            string        `json:"role" bson:"role" validate:"oneof=ADMIN USER"`
 	CreatedAt       time.Time     `json:"created_at" bson:"created_at"`
 	UpdatedAt       time.Time     `json:"update_at" bson:"update_at"`
-	Token           string        `json:"token" bson:"token"`
-	RefreshToken    string        `json:"refresh_token" bson:"refresh_token"`
 	FavouriteGenres []Genre       `json:"favourite_genres" bson:"favourite_genres" validate:"required,dive"`
+	EmailVerified   bool          `json:"email_verified" bson:"email_verified"`
+	EmailVerifiedAt *time.Time    `json:"email_verified_at,omitempty" bson:"email_verified_at,omitempty"`
+	// PendingEmail is an address the user has asked to change to. Email
+	// only changes once the new address is verified.
+	PendingEmail    string        `json:"pending_email,omitempty" bson:"pending_email,omitempty"`
+	// LockedUntil is set when too many failed logins lock the account.
+	LockedUntil     *time.Time    `json:"locked_until,omitempty" bson:"locked_until,omitempty"`
+	Status          string        `json:"status,omitempty" bson:"status,omitempty"`
+	DeletedAt       *time.Time    `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+}
+
+// AccountStatus returns the user's status, UserActive when none is stored.
+func (u User) AccountStatus() string {
+	if u.Status == "" {
+		return UserActive
+	}
+	return u.Status
+}
+// RegisterRequest is a /register body. It only carries what a new user
+// chooses; the ID, role, status and timestamps are set by the server, so a
+// client cannot register itself as an admin.
+type RegisterRequest struct {
+	FirstName       string  `json:"first_name" validate:"required,min=2,max=100"`
+	LastName        string  `json:"last_name" validate:"required,min=2,max=100"`
+	Email           string  `json:"email" validate:"required,email"`
+	Password        string  `json:"password" validate:"required,min=6"`
+	FavouriteGenres []Genre `json:"favourite_genres" validate:"required,dive"`
 }
+
 type UserLogin struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=6"`
 }
+// UserResponse is the login response: the profile and the new token pair.
 type UserResponse struct {
 	UserId          string  `json:"user_id"`
 	FirstName       string  `json:"first_name"`
@@ -32,4 +67,57 @@ type UserResponse struct {
 	Token           string  `json:"token"`
 	RefreshToken    string  `json:"refresh_token"`
 	FavouriteGenres []Genre `json:"favourite_genres"`
-}
\ No newline at end of file
+}
+
+// EmailRequest carries the address for the forgotten password and resend
+// verification requests.
+type EmailRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// PasswordResetRequest sets a new password with a reset token.
+type PasswordResetRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=6"`
+}
+
+// ProfileResponse is a user as shown to themselves and to admins. It never
+// carries the password hash or any token.
+type ProfileResponse struct {
+	UserID          string     `json:"user_id"`
+	FirstName       string     `json:"first_name"`
+	LastName        string     `json:"last_name"`
+	Email           string     `json:"email"`
+	PendingEmail    string     `json:"pending_email,omitempty"`
+	EmailVerified   bool       `json:"email_verified"`
+	Role            string     `json:"role"`
+	Status          string     `json:"status"`
+	FavouriteGenres []Genre    `json:"favourite_genres"`
+	LockedUntil     *time.Time `json:"locked_until,omitempty"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// ProfileUpdate is a PATCH /me body; omitted fields stay unchanged.
+// Changing the email or password needs CurrentPassword.
+type ProfileUpdate struct {
+	FirstName       *string `json:"first_name" validate:"omitempty,min=2,max=100"`
+	LastName        *string `json:"last_name" validate:"omitempty,min=2,max=100"`
+	Email           *string `json:"email" validate:"omitempty,email"`
+	NewPassword     *string `json:"new_password" validate:"omitempty,min=6"`
+	CurrentPassword string  `json:"current_password"`
+}
+
+// FavouriteGenresUpdate replaces a user's favourite genres. Only the IDs are
+// taken from the client; the names come from the genre list.
+type FavouriteGenresUpdate struct {
+	GenreIDs []int `json:"genre_ids" validate:"required,max=50,unique,dive,gt=0"`
+}
+
+// AdminUserUpdate is a PATCH /admin/users/:user_id body; omitted fields stay
+// unchanged.
+type AdminUserUpdate struct {
+	Role   *string `json:"role" validate:"omitempty,oneof=ADMIN USER"`
+	Status *string `json:"status" validate:"omitempty,oneof=active disabled"`
+}
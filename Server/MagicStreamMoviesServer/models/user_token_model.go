@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Purposes of a UserToken.
+const (
+	PurposeVerifyEmail   = "verify_email"
+	PurposeResetPassword = "reset_password"
+)
+
+// UserToken is a single-use token mailed to a user to prove they control
+// their address. Only the SHA-256 of the token is stored; the token itself
+// only exists in the email.
+type UserToken struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	UserID    string             `bson:"user_id" json:"-"`
+	Purpose   string             `bson:"purpose" json:"-"`
+	TokenHash string             `bson:"token_hash" json:"-"`
+	// Email is the address the token was sent to. Verifying it only counts
+	// while the user still has that address.
+	Email     string    `bson:"email" json:"-"`
+	CreatedAt time.Time `bson:"created_at" json:"-"`
+	// ExpiresAt is when the token stops working; a TTL index removes it then.
+	ExpiresAt time.Time  `bson:"expires_at" json:"-"`
+	UsedAt    *time.Time `bson:"used_at,omitempty" json:"-"`
+}
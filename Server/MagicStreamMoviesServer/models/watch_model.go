@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WatchlistItem is a movie a user has saved to watch later.
+type WatchlistItem struct {
+	ID      primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	UserID  string             `bson:"user_id" json:"user_id"`
+	ImdbID  string             `bson:"imdb_id" json:"imdb_id"`
+	AddedAt time.Time          `bson:"added_at" json:"added_at"`
+	Movie   *Movie             `bson:"-" json:"movie,omitempty"`
+}
+
+type WatchlistRequest struct {
+	ImdbID string `json:"imdb_id" validate:"required"`
+}
+
+// WatchHistory is a user's playback progress on a movie. There is one entry
+// per (UserID, ImdbID), updated by every playback event. Finished stays true
+// once the movie has been watched to the end.
+type WatchHistory struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	UserID         string             `bson:"user_id" json:"user_id"`
+	ImdbID         string             `bson:"imdb_id" json:"imdb_id"`
+	SecondsWatched int                `bson:"seconds_watched" json:"seconds_watched"`
+	Finished       bool               `bson:"finished" json:"finished"`
+	FinishedAt     *time.Time         `bson:"finished_at,omitempty" json:"finished_at,omitempty"`
+	LastWatchedAt  time.Time          `bson:"last_watched_at" json:"last_watched_at"`
+	Movie          *Movie             `bson:"-" json:"movie,omitempty"`
+}
+
+// PlaybackEvent reports how far a user got through a movie.
+type PlaybackEvent struct {
+	ImdbID         string `json:"imdb_id" validate:"required"`
+	SecondsWatched int    `json:"seconds_watched" validate:"min=0"`
+	Finished       bool   `json:"finished"`
+}
@@ -0,0 +1,64 @@
+package recommendation
+
+import (
+	"strings"
+
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/ai"
+	models "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/repository"
+)
+
+// ColdStartStrategy decides what to recommend when the scorer has nothing to
+// go on: the user has no favourite genres, or no movie matches them.
+type ColdStartStrategy string
+
+const (
+	// TopRanked recommends ranked movies best first, skipping Not_Ranked.
+	TopRanked ColdStartStrategy = "top_ranked"
+	// Newest recommends the most recently added movies.
+	Newest ColdStartStrategy = "newest"
+	// NoColdStart returns an empty list.
+	NoColdStart ColdStartStrategy = "none"
+)
+
+// ParseColdStartStrategy validates a strategy name. The empty string selects
+// TopRanked.
+func ParseColdStartStrategy(name string) (ColdStartStrategy, bool) {
+	switch strategy := ColdStartStrategy(strings.ToLower(strings.TrimSpace(name))); strategy {
+	case "":
+		return TopRanked, true
+	case TopRanked, Newest, NoColdStart:
+		return strategy, true
+	default:
+		return "", false
+	}
+}
+
+// Query returns the movie filter and sort for the strategy. ok is false for
+// NoColdStart, in which case nothing should be fetched.
+func (s ColdStartStrategy) Query() (filter repository.MovieFilter, sort repository.MovieSort, ok bool) {
+	switch s {
+	case Newest:
+		return filter, repository.MovieSort{Field: repository.SortByCreated, Descending: true}, true
+	case NoColdStart:
+		return filter, sort, false
+	default:
+		best, worst := 1, ai.NotRankedValue-1
+		filter = repository.MovieFilter{MinRanking: &best, MaxRanking: &worst}
+		return filter, repository.MovieSort{Field: repository.SortByRanking}, true
+	}
+}
+
+// Wrap turns cold-start movies into recommendations with a reason explaining
+// why each was picked. They carry no score since nothing was personalised.
+func (s ColdStartStrategy) Wrap(movies []models.Movie) []models.RecommendedMovie {
+	recommended := make([]models.RecommendedMovie, len(movies))
+	for i, movie := range movies {
+		reason := "Recently added"
+		if s != Newest {
+			reason = "Top ranked: " + strings.TrimSpace(movie.Ranking.RankingName)
+		}
+		recommended[i] = models.RecommendedMovie{Movie: movie, Reasons: []string{reason}}
+	}
+	return recommended
+}
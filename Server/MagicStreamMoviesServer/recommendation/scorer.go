@@ -0,0 +1,130 @@
+package recommendation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/ai"
+	models "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+)
+
+// Profile is what the scorer knows about the user it is recommending for.
+// Watched and Rated are keyed by imdb_id.
+type Profile struct {
+	FavouriteGenres []models.Genre
+	Watched         map[string]bool
+	Rated           map[string]bool
+}
+
+// Scorer ranks candidate movies for a profile. A movie's score is a weighted
+// blend of how much of it falls in the user's favourite genres and how well
+// it is ranked, reduced for titles the user has already seen or rated.
+type Scorer struct {
+	GenreWeight   float64
+	RankingWeight float64
+	// BestRanking and WorstRanking bound the ranking scale; lower is better.
+	BestRanking  int
+	WorstRanking int
+	// NotRankedScore is the ranking component for Not_Ranked movies.
+	NotRankedScore float64
+	// WatchedPenalty and RatedPenalty multiply the score of movies the user
+	// has already watched or rated.
+	WatchedPenalty float64
+	RatedPenalty   float64
+}
+
+// DefaultScorer favours genre fit over ranking and matches the seed ranking
+// scale of 1 (Excellent) to 5 (Terrible).
+func DefaultScorer() Scorer {
+	return Scorer{
+		GenreWeight:    0.6,
+		RankingWeight:  0.4,
+		BestRanking:    1,
+		WorstRanking:   5,
+		NotRankedScore: 0.3,
+		WatchedPenalty: 0.2,
+		RatedPenalty:   0.5,
+	}
+}
+
+func (s Scorer) rankingScore(ranking models.Ranking) (float64, string) {
+	if ranking.RankingValue == ai.NotRankedValue || ranking.RankingValue < s.BestRanking {
+		return s.NotRankedScore, "Not ranked yet"
+	}
+	value := ranking.RankingValue
+	if value > s.WorstRanking {
+		value = s.WorstRanking
+	}
+	score := 1.0
+	if s.WorstRanking > s.BestRanking {
+		score = 1 - float64(value-s.BestRanking)/float64(s.WorstRanking-s.BestRanking)
+	}
+	return score, fmt.Sprintf("Ranked %s", strings.TrimSpace(ranking.RankingName))
+}
+
+// Score rates a single movie. Movies that share no genre with the profile
+// score zero and carry no reasons.
+func (s Scorer) Score(profile Profile, movie models.Movie) (float64, []string) {
+	favourites := make(map[int]bool, len(profile.FavouriteGenres))
+	for _, genre := range profile.FavouriteGenres {
+		favourites[genre.GenreID] = true
+	}
+
+	var matched []string
+	for _, genre := range movie.Genre {
+		if favourites[genre.GenreID] {
+			matched = append(matched, genre.GenreName)
+		}
+	}
+	if len(matched) == 0 {
+		return 0, nil
+	}
+
+	genreScore := float64(len(matched)) / float64(len(movie.Genre))
+	reasons := []string{fmt.Sprintf("Matches %d of %d genres you like: %s", len(matched), len(movie.Genre), strings.Join(matched, ", "))}
+
+	rankingScore, rankingReason := s.rankingScore(movie.Ranking)
+	reasons = append(reasons, rankingReason)
+
+	score := s.GenreWeight*genreScore + s.RankingWeight*rankingScore
+	if profile.Watched[movie.ImdbID] {
+		score *= s.WatchedPenalty
+		reasons = append(reasons, "You have already watched this")
+	}
+	if profile.Rated[movie.ImdbID] {
+		score *= s.RatedPenalty
+		reasons = append(reasons, "You have already rated this")
+	}
+	return score, reasons
+}
+
+// Recommend scores every candidate and returns the best limit movies, highest
+// score first. Ties go to the better ranked movie, then to title order, so the
+// result is stable.
+func (s Scorer) Recommend(profile Profile, candidates []models.Movie, limit int) []models.RecommendedMovie {
+	recommended := make([]models.RecommendedMovie, 0, len(candidates))
+	for _, movie := range candidates {
+		score, reasons := s.Score(profile, movie)
+		if score <= 0 {
+			continue
+		}
+		recommended = append(recommended, models.RecommendedMovie{Movie: movie, Score: score, Reasons: reasons})
+	}
+
+	sort.SliceStable(recommended, func(i, j int) bool {
+		a, b := recommended[i], recommended[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Ranking.RankingValue != b.Ranking.RankingValue {
+			return a.Ranking.RankingValue < b.Ranking.RankingValue
+		}
+		return a.Title < b.Title
+	})
+
+	if len(recommended) > limit {
+		recommended = recommended[:limit]
+	}
+	return recommended
+}
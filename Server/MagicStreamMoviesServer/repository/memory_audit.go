@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	models "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryAudit struct {
+	mu      sync.RWMutex
+	entries []models.AuditEntry
+}
+
+func (r *memoryAudit) Record(ctx context.Context, entry models.AuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if entry.ID.IsZero() {
+		entry.ID = primitive.NewObjectID()
+	}
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func (r *memoryAudit) Page(ctx context.Context, filter AuditFilter, offset, limit int) (Page[models.AuditEntry], error) {
+	r.mu.RLock()
+	entries := []models.AuditEntry{}
+	for _, entry := range r.entries {
+		if (filter.ActorID == "" || entry.ActorID == filter.ActorID) &&
+			(filter.TargetID == "" || entry.TargetID == filter.TargetID) {
+			entries = append(entries, entry)
+		}
+	}
+	r.mu.RUnlock()
+
+	return offsetPage(entries, func(a, b models.AuditEntry) int {
+		return newestFirst(a.At, b.At, a.ID, b.ID)
+	}, offset, limit), nil
+}
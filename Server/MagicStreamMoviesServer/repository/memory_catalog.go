@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+
+	models "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+)
+
+// memoryGenres keeps the genres ordered by ID.
+type memoryGenres struct {
+	mu     sync.RWMutex
+	genres []models.Genre
+}
+
+func compareGenres(a, b models.Genre) int {
+	return cmp.Compare(a.GenreID, b.GenreID)
+}
+
+func newMemoryGenres(seed []models.Genre) *memoryGenres {
+	genres := slices.Clone(seed)
+	slices.SortFunc(genres, compareGenres)
+	return &memoryGenres{genres: genres}
+}
+
+func (r *memoryGenres) List(ctx context.Context) ([]models.Genre, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]models.Genre{}, r.genres...), nil
+}
+
+// find returns the index of the genre, and whether it exists. The caller
+// holds the lock.
+func (r *memoryGenres) find(genreID int) (int, bool) {
+	return slices.BinarySearchFunc(r.genres, models.Genre{GenreID: genreID}, compareGenres)
+}
+
+func (r *memoryGenres) Get(ctx context.Context, genreID int) (models.Genre, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	i, ok := r.find(genreID)
+	if !ok {
+		return models.Genre{}, ErrNotFound
+	}
+	return r.genres[i], nil
+}
+
+func (r *memoryGenres) Insert(ctx context.Context, genre models.Genre) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, ok := r.find(genre.GenreID)
+	if ok {
+		return ErrDuplicate
+	}
+	r.genres = slices.Insert(r.genres, i, genre)
+	return nil
+}
+
+func (r *memoryGenres) Rename(ctx context.Context, genreID int, name string) (models.Genre, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, ok := r.find(genreID)
+	if !ok {
+		return models.Genre{}, ErrNotFound
+	}
+	r.genres[i].GenreName = name
+	return r.genres[i], nil
+}
+
+func (r *memoryGenres) Delete(ctx context.Context, genreID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, ok := r.find(genreID)
+	if !ok {
+		return ErrNotFound
+	}
+	r.genres = slices.Delete(r.genres, i, i+1)
+	return nil
+}
+
+// replaceGenre returns genres with the genre with ID from swapped for to, as
+// described by MovieRepository.ReplaceGenre, and whether anything changed.
+func replaceGenre(genres []models.Genre, from int, to *models.Genre) ([]models.Genre, bool) {
+	if !slices.ContainsFunc(genres, func(genre models.Genre) bool { return genre.GenreID == from }) {
+		return genres, false
+	}
+
+	replaced := make([]models.Genre, 0, len(genres))
+	for _, genre := range genres {
+		if genre.GenreID == from {
+			if to == nil {
+				continue
+			}
+			genre = *to
+		}
+		if !slices.ContainsFunc(replaced, func(listed models.Genre) bool { return listed.GenreID == genre.GenreID }) {
+			replaced = append(replaced, genre)
+		}
+	}
+	return replaced, true
+}
+
+type memoryRankings struct {
+	mu       sync.RWMutex
+	rankings []models.Ranking
+}
+
+func newMemoryRankings(seed []models.Ranking) *memoryRankings {
+	rankings := slices.Clone(seed)
+	slices.SortFunc(rankings, func(a, b models.Ranking) int {
+		return cmp.Compare(a.RankingValue, b.RankingValue)
+	})
+	return &memoryRankings{rankings: rankings}
+}
+
+func (r *memoryRankings) List(ctx context.Context) ([]models.Ranking, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]models.Ranking{}, r.rankings...), nil
+}
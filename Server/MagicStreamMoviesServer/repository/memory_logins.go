@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	models "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+)
+
+// memoryLogins keeps login failure counters keyed like the Mongo _id. Expired
+// counters are treated as missing and dropped when next written.
+type memoryLogins struct {
+	mu       sync.Mutex
+	attempts map[string]models.LoginAttempt
+}
+
+func (r *memoryLogins) Get(ctx context.Context, key string) (models.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempt, ok := r.attempts[key]
+	if !ok || !attempt.ExpiresAt.After(time.Now()) {
+		return models.LoginAttempt{}, ErrNotFound
+	}
+	return attempt, nil
+}
+
+func (r *memoryLogins) RecordFailure(ctx context.Context, key string, at, forgetBefore, expiresAt time.Time) (models.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempt, ok := r.attempts[key]
+	if !ok || !attempt.LastFailureAt.After(forgetBefore) {
+		attempt = models.LoginAttempt{Key: key}
+	}
+	attempt.Failures++
+	attempt.LastFailureAt = at
+	attempt.ExpiresAt = expiresAt
+	r.attempts[key] = attempt
+	return attempt, nil
+}
+
+func (r *memoryLogins) Reset(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.attempts, key)
+	return nil
+}
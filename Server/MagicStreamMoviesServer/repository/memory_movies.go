@@ -0,0 +1,308 @@
+package repository
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	models "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryMovies keeps movies keyed by imdb_id, including soft deleted ones.
+type memoryMovies struct {
+	mu       sync.RWMutex
+	movies   map[string]models.Movie
+	revision atomic.Uint64
+}
+
+func newMemoryMovies(seed []models.Movie) *memoryMovies {
+	r := &memoryMovies{movies: map[string]models.Movie{}}
+	for _, movie := range seed {
+		if movie.ID.IsZero() {
+			movie.ID = primitive.NewObjectID()
+		}
+		r.movies[movie.ImdbID] = cloneMovie(movie)
+	}
+	return r
+}
+
+// cloneMovie copies the slices and pointers of a movie so callers never share
+// memory with the store.
+func cloneMovie(movie models.Movie) models.Movie {
+	movie.Genre = slices.Clone(movie.Genre)
+	if movie.DeletedAt != nil {
+		deletedAt := *movie.DeletedAt
+		movie.DeletedAt = &deletedAt
+	}
+	return movie
+}
+
+func compareObjectIDs(a, b primitive.ObjectID) int {
+	return bytes.Compare(a[:], b[:])
+}
+
+// compareMovies orders two movies the way movieSort orders them in Mongo.
+func compareMovies(a, b models.Movie, s MovieSort) int {
+	var order int
+	switch s.Field {
+	case SortByTitle:
+		order = strings.Compare(a.Title, b.Title)
+	case SortByRanking:
+		order = cmp.Compare(a.Ranking.RankingValue, b.Ranking.RankingValue)
+	}
+	if order == 0 {
+		order = compareObjectIDs(a.ID, b.ID)
+	}
+	if s.Descending {
+		order = -order
+	}
+	return order
+}
+
+// cursorMovie builds a movie positioned at the cursor so it can be compared
+// with compareMovies.
+func cursorMovie(s MovieSort, cursor *Cursor) models.Movie {
+	movie := models.Movie{ID: cursor.ID}
+	switch s.Field {
+	case SortByTitle:
+		movie.Title, _ = cursor.Value.(string)
+	case SortByRanking:
+		movie.Ranking.RankingValue, _ = cursor.Value.(int)
+	}
+	return movie
+}
+
+func (f MovieFilter) matches(movie models.Movie) bool {
+	if movie.DeletedAt != nil {
+		return false
+	}
+	if len(f.GenreIDs) > 0 && !slices.ContainsFunc(movie.Genre, func(g models.Genre) bool {
+		return slices.Contains(f.GenreIDs, g.GenreID)
+	}) {
+		return false
+	}
+	if f.MinRanking != nil && movie.Ranking.RankingValue < *f.MinRanking {
+		return false
+	}
+	if f.MaxRanking != nil && movie.Ranking.RankingValue > *f.MaxRanking {
+		return false
+	}
+	if !strings.HasPrefix(movie.Title, f.TitlePrefix) {
+		return false
+	}
+	return !slices.Contains(f.ExcludeImdbIDs, movie.ImdbID)
+}
+
+// find returns copies of the matching movies in order. The caller must hold
+// the lock.
+func (r *memoryMovies) find(filter MovieFilter, sort MovieSort) []models.Movie {
+	movies := []models.Movie{}
+	for _, movie := range r.movies {
+		if filter.matches(movie) {
+			movies = append(movies, cloneMovie(movie))
+		}
+	}
+	slices.SortFunc(movies, func(a, b models.Movie) int {
+		return compareMovies(a, b, sort)
+	})
+	return movies
+}
+
+func (r *memoryMovies) Revision() uint64 {
+	return r.revision.Load()
+}
+
+func (r *memoryMovies) Page(ctx context.Context, q MovieQuery) (Page[models.Movie], error) {
+	r.mu.RLock()
+	movies := r.find(q.Filter, q.Sort)
+	r.mu.RUnlock()
+
+	page := Page[models.Movie]{Total: int64(len(movies))}
+	if q.After != nil {
+		after := cursorMovie(q.Sort, q.After)
+		start := slices.IndexFunc(movies, func(movie models.Movie) bool {
+			return compareMovies(movie, after, q.Sort) > 0
+		})
+		if start < 0 {
+			start = len(movies)
+		}
+		movies = movies[start:]
+	}
+	if len(movies) > q.Limit {
+		movies = movies[:q.Limit]
+		page.More = true
+	}
+	page.Items = movies
+	return page, nil
+}
+
+func (r *memoryMovies) Find(ctx context.Context, filter MovieFilter, sort MovieSort, limit int) ([]models.Movie, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	movies := r.find(filter, sort)
+	if limit > 0 && len(movies) > limit {
+		movies = movies[:limit]
+	}
+	return movies, nil
+}
+
+func (r *memoryMovies) FindByImdbIDs(ctx context.Context, ids []string) ([]models.Movie, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	movies := []models.Movie{}
+	for _, id := range ids {
+		if movie, ok := r.movies[id]; ok && movie.DeletedAt == nil {
+			movies = append(movies, cloneMovie(movie))
+		}
+	}
+	return movies, nil
+}
+
+func (r *memoryMovies) Get(ctx context.Context, imdbID string) (models.Movie, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	movie, ok := r.movies[imdbID]
+	if !ok || movie.DeletedAt != nil {
+		return models.Movie{}, ErrNotFound
+	}
+	return cloneMovie(movie), nil
+}
+
+func (r *memoryMovies) Exists(ctx context.Context, imdbID string) (bool, error) {
+	_, err := r.Get(ctx, imdbID)
+	if err == ErrNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *memoryMovies) TextSearch(ctx context.Context, q string, offset, limit int) ([]models.MovieSearchResult, int64, error) {
+	return nil, 0, ErrTextSearchUnsupported
+}
+
+func (r *memoryMovies) Insert(ctx context.Context, movie *models.Movie) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.movies[movie.ImdbID]; ok {
+		return ErrDuplicate
+	}
+	if movie.ID.IsZero() {
+		movie.ID = primitive.NewObjectID()
+	}
+	r.movies[movie.ImdbID] = cloneMovie(*movie)
+	r.revision.Add(1)
+	return nil
+}
+
+// setEditable copies the admin-editable fields of from onto movie.
+func setEditable(movie *models.Movie, from models.Movie) {
+	movie.ImdbID = from.ImdbID
+	movie.Title = from.Title
+	movie.PosterPath = from.PosterPath
+	movie.YouTubeID = from.YouTubeID
+	movie.Genre = slices.Clone(from.Genre)
+	movie.AdminReview = from.AdminReview
+	movie.Ranking = from.Ranking
+}
+
+// update applies change to the movie with the given imdb_id when it is in
+// the requested deleted state, and returns a copy of the result.
+func (r *memoryMovies) update(imdbID string, deleted bool, change func(movie *models.Movie)) (models.Movie, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	movie, ok := r.movies[imdbID]
+	if !ok || (movie.DeletedAt != nil) != deleted {
+		return models.Movie{}, ErrNotFound
+	}
+	change(&movie)
+	r.movies[imdbID] = movie
+	r.revision.Add(1)
+	return cloneMovie(movie), nil
+}
+
+func (r *memoryMovies) Replace(ctx context.Context, imdbID string, movie models.Movie) (models.Movie, error) {
+	return r.update(imdbID, false, func(stored *models.Movie) {
+		setEditable(stored, movie)
+	})
+}
+
+func (r *memoryMovies) Upsert(ctx context.Context, movie models.Movie) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.movies[movie.ImdbID]
+	if ok && stored.DeletedAt != nil {
+		return false, ErrDeleted
+	}
+	if !ok {
+		stored = models.Movie{ID: primitive.NewObjectID()}
+	}
+	setEditable(&stored, movie)
+	r.movies[movie.ImdbID] = stored
+	r.revision.Add(1)
+	return !ok, nil
+}
+
+func (r *memoryMovies) SetReview(ctx context.Context, imdbID, review string, ranking models.Ranking) (models.Movie, error) {
+	return r.update(imdbID, false, func(movie *models.Movie) {
+		movie.AdminReview = review
+		movie.Ranking = ranking
+	})
+}
+
+func (r *memoryMovies) SoftDelete(ctx context.Context, imdbID string, at time.Time) error {
+	_, err := r.update(imdbID, false, func(movie *models.Movie) {
+		movie.DeletedAt = &at
+	})
+	return err
+}
+
+func (r *memoryMovies) Restore(ctx context.Context, imdbID string) (models.Movie, error) {
+	return r.update(imdbID, true, func(movie *models.Movie) {
+		movie.DeletedAt = nil
+	})
+}
+
+func (r *memoryMovies) CountWithGenre(ctx context.Context, genreID int) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, movie := range r.movies {
+		if slices.ContainsFunc(movie.Genre, func(genre models.Genre) bool { return genre.GenreID == genreID }) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memoryMovies) ReplaceGenre(ctx context.Context, from int, to *models.Genre) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var changed int64
+	for imdbID, movie := range r.movies {
+		genres, ok := replaceGenre(movie.Genre, from, to)
+		if !ok {
+			continue
+		}
+		movie.Genre = genres
+		r.movies[imdbID] = movie
+		changed++
+	}
+	if changed > 0 {
+		r.revision.Add(1)
+	}
+	return changed, nil
+}
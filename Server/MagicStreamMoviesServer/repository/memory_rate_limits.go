@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	models "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+)
+
+// rateLimitSweepInterval is how often full buckets are dropped from memory.
+const rateLimitSweepInterval = time.Minute
+
+// memoryRateLimits keeps buckets in process memory. Buckets that have
+// refilled completely are indistinguishable from new ones, so they are swept
+// away periodically.
+type memoryRateLimits struct {
+	mu        sync.Mutex
+	buckets   map[string]models.RateLimitBucket
+	nextSweep time.Time
+}
+
+// NewMemoryRateLimits returns rate limit buckets local to this process, for
+// deployments that store everything else in MongoDB but do not share limits
+// between instances.
+func NewMemoryRateLimits() RateLimitRepository {
+	return &memoryRateLimits{buckets: map[string]models.RateLimitBucket{}}
+}
+
+// refill computes a bucket after the time since its last update, starting
+// a missing one full.
+func refill(bucket models.RateLimitBucket, found bool, capacity int, rate float64, now time.Time) models.RateLimitBucket {
+	tokens := float64(capacity)
+	if found {
+		elapsed := max(now.Sub(bucket.UpdatedAt).Seconds(), 0)
+		tokens = math.Min(float64(capacity), bucket.Tokens+elapsed*rate)
+	}
+	bucket.Allowed = tokens >= 1
+	if bucket.Allowed {
+		tokens--
+	}
+	bucket.Tokens = tokens
+	bucket.UpdatedAt = now
+	bucket.ExpiresAt = now.Add(time.Duration((float64(capacity) - tokens) / rate * float64(time.Second)))
+	return bucket
+}
+
+func (r *memoryRateLimits) Take(ctx context.Context, key string, capacity int, rate float64, now time.Time) (models.RateLimitBucket, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if now.After(r.nextSweep) {
+		for k, bucket := range r.buckets {
+			if !bucket.ExpiresAt.After(now) {
+				delete(r.buckets, k)
+			}
+		}
+		r.nextSweep = now.Add(rateLimitSweepInterval)
+	}
+
+	bucket, found := r.buckets[key]
+	bucket = refill(bucket, found, capacity, rate, now)
+	bucket.Key = key
+	r.buckets[key] = bucket
+	return bucket, nil
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sync"
+
+	models "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryRatings struct {
+	mu      sync.RWMutex
+	ratings map[watchKey]models.Rating
+	// movies receives the audience scores.
+	movies *memoryMovies
+}
+
+func (f RatingFilter) matches(rating models.Rating) bool {
+	return (f.UserID == "" || rating.UserID == f.UserID) &&
+		(f.ImdbID == "" || rating.ImdbID == f.ImdbID)
+}
+
+func (r *memoryRatings) Upsert(ctx context.Context, rating models.Rating) (models.Rating, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := watchKey{rating.UserID, rating.ImdbID}
+	stored, ok := r.ratings[key]
+	if !ok {
+		stored = models.Rating{
+			ID:        primitive.NewObjectID(),
+			UserID:    rating.UserID,
+			ImdbID:    rating.ImdbID,
+			CreatedAt: rating.UpdatedAt,
+		}
+	}
+	stored.Stars = rating.Stars
+	stored.Review = rating.Review
+	stored.UpdatedAt = rating.UpdatedAt
+	r.ratings[key] = stored
+	return stored, !ok, nil
+}
+
+func (r *memoryRatings) Delete(ctx context.Context, userID, imdbID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := watchKey{userID, imdbID}
+	if _, ok := r.ratings[key]; !ok {
+		return ErrNotFound
+	}
+	delete(r.ratings, key)
+	return nil
+}
+
+func (r *memoryRatings) Page(ctx context.Context, filter RatingFilter, after primitive.ObjectID, limit int) (Page[models.Rating], error) {
+	r.mu.RLock()
+	var page Page[models.Rating]
+	ratings := []models.Rating{}
+	for _, rating := range r.ratings {
+		if filter.matches(rating) {
+			page.Total++
+			if after.IsZero() || compareObjectIDs(rating.ID, after) < 0 {
+				ratings = append(ratings, rating)
+			}
+		}
+	}
+	r.mu.RUnlock()
+
+	slices.SortFunc(ratings, func(a, b models.Rating) int {
+		return compareObjectIDs(b.ID, a.ID)
+	})
+	if len(ratings) > limit {
+		ratings = ratings[:limit]
+		page.More = true
+	}
+	page.Items = ratings
+	return page, nil
+}
+
+func (r *memoryRatings) SyncAudienceScore(ctx context.Context, imdbID string) error {
+	// Holding the ratings lock while writing the movie keeps a concurrent
+	// rating change from landing between the two
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	total, count := 0, 0
+	for _, rating := range r.ratings {
+		if rating.ImdbID == imdbID {
+			total += rating.Stars
+			count++
+		}
+	}
+	score := 0.0
+	if count > 0 {
+		score = math.Round(float64(total)/float64(count)*100) / 100
+	}
+
+	r.movies.mu.Lock()
+	defer r.movies.mu.Unlock()
+
+	// Like the Mongo update, this applies to deleted movies too and does not
+	// count as a catalogue change.
+	if movie, ok := r.movies.movies[imdbID]; ok {
+		movie.AudienceScore = score
+		movie.RatingCount = count
+		r.movies.movies[imdbID] = movie
+	}
+	return nil
+}
+
+func (r *memoryRatings) RatedImdbIDs(ctx context.Context, userID string) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := []string{}
+	for key := range r.ratings {
+		if key.userID == userID {
+			ids = append(ids, key.imdbID)
+		}
+	}
+	return ids, nil
+}
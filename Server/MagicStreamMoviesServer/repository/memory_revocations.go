@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	models "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryRevocations drops expired entries whenever a new one is added, which
+// keeps the list as short as the TTL index keeps the collection.
+type memoryRevocations struct {
+	mu          sync.RWMutex
+	revocations []models.Revocation
+}
+
+func (r *memoryRevocations) Revoke(ctx context.Context, revocation models.Revocation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	kept := r.revocations[:0]
+	for _, existing := range r.revocations {
+		if existing.ExpiresAt.After(now) {
+			kept = append(kept, existing)
+		}
+	}
+	if revocation.ID.IsZero() {
+		revocation.ID = primitive.NewObjectID()
+	}
+	r.revocations = append(kept, revocation)
+	return nil
+}
+
+func (r *memoryRevocations) IsRevoked(ctx context.Context, tokenID, sessionID, userID string, issuedAt time.Time) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := time.Now()
+	for _, revocation := range r.revocations {
+		if revocation.ExpiresAt.After(now) && revocation.Matches(tokenID, sessionID, userID, issuedAt) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	models "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memorySessions keeps sessions keyed by _id. Expired sessions are never
+// removed, which is harmless for a store that lives as long as the process.
+type memorySessions struct {
+	mu       sync.RWMutex
+	sessions map[primitive.ObjectID]models.Session
+}
+
+func (r *memorySessions) Create(ctx context.Context, session *models.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.create(session)
+}
+
+func (r *memorySessions) create(session *models.Session) error {
+	for _, existing := range r.sessions {
+		if existing.RefreshTokenHash == session.RefreshTokenHash {
+			return ErrDuplicate
+		}
+	}
+	if session.ID.IsZero() {
+		session.ID = primitive.NewObjectID()
+	}
+	r.sessions[session.ID] = *session
+	return nil
+}
+
+func (r *memorySessions) GetByTokenHash(ctx context.Context, hash string) (models.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, session := range r.sessions {
+		if session.RefreshTokenHash == hash {
+			return session, nil
+		}
+	}
+	return models.Session{}, ErrNotFound
+}
+
+// revoke marks every unrevoked session that matches as revoked.
+func (r *memorySessions) revoke(match func(models.Session) bool, reason string, at time.Time) int {
+	revoked := 0
+	for id, session := range r.sessions {
+		if session.RevokedAt == nil && match(session) {
+			session.RevokedAt = &at
+			session.RevokedReason = reason
+			r.sessions[id] = session
+			revoked++
+		}
+	}
+	return revoked
+}
+
+func (r *memorySessions) Rotate(ctx context.Context, current primitive.ObjectID, next *models.Session, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	revoked := r.revoke(func(session models.Session) bool {
+		return session.ID == current
+	}, models.SessionRotated, at)
+	if revoked == 0 {
+		return ErrNotFound
+	}
+	return r.create(next)
+}
+
+func (r *memorySessions) RevokeFamily(ctx context.Context, userID, familyID, reason string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	revoked := r.revoke(func(session models.Session) bool {
+		return session.UserID == userID && session.FamilyID == familyID
+	}, reason, at)
+	if revoked == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *memorySessions) RevokeUser(ctx context.Context, userID, reason string, at time.Time) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	families := []string{}
+	r.revoke(func(session models.Session) bool {
+		if session.UserID != userID {
+			return false
+		}
+		families = append(families, session.FamilyID)
+		return true
+	}, reason, at)
+	return families, nil
+}
+
+func (r *memorySessions) ListActive(ctx context.Context, userID string, now time.Time) ([]models.Session, error) {
+	r.mu.RLock()
+	sessions := []models.Session{}
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.Active(now) {
+			sessions = append(sessions, session)
+		}
+	}
+	r.mu.RUnlock()
+
+	slices.SortFunc(sessions, func(a, b models.Session) int {
+		return newestFirst(a.LastUsedAt, b.LastUsedAt, a.ID, b.ID)
+	})
+	return sessions, nil
+}
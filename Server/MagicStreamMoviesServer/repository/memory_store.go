@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/fixtures"
+	models "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NewMemoryStore returns repositories that keep everything in process memory,
+// starting from the given fixtures. Nothing survives a restart, which makes it
+// suited to tests and local development without MongoDB.
+func NewMemoryStore(seed fixtures.Set) *Store {
+	movies := newMemoryMovies(seed.Movies)
+	return &Store{
+		Movies:      movies,
+		Users:       newMemoryUsers(seed.Users),
+		Sessions:    &memorySessions{sessions: map[primitive.ObjectID]models.Session{}},
+		Revocations: &memoryRevocations{},
+		UserTokens:  &memoryUserTokens{tokens: map[string]models.UserToken{}},
+		Logins:      &memoryLogins{attempts: map[string]models.LoginAttempt{}},
+		RateLimits:  NewMemoryRateLimits(),
+		Audit:       &memoryAudit{},
+		Genres:      newMemoryGenres(seed.Genres),
+		Rankings:    newMemoryRankings(seed.Rankings),
+		Ratings:     &memoryRatings{ratings: map[watchKey]models.Rating{}, movies: movies},
+		Watchlist:   &memoryWatchlist{items: map[watchKey]models.WatchlistItem{}},
+		History:     &memoryHistory{entries: map[watchKey]models.WatchHistory{}},
+	}
+}
+
+// watchKey identifies the one record a user may have per movie.
+type watchKey struct {
+	userID string
+	imdbID string
+}
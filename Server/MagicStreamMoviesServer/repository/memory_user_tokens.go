@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	models "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryUserTokens keeps tokens keyed by hash and drops expired ones
+// whenever a new one is created.
+type memoryUserTokens struct {
+	mu     sync.Mutex
+	tokens map[string]models.UserToken
+}
+
+func (r *memoryUserTokens) Create(ctx context.Context, token *models.UserToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for hash, existing := range r.tokens {
+		if !existing.ExpiresAt.After(now) {
+			delete(r.tokens, hash)
+		}
+	}
+	if _, ok := r.tokens[token.TokenHash]; ok {
+		return ErrDuplicate
+	}
+	if token.ID.IsZero() {
+		token.ID = primitive.NewObjectID()
+	}
+	r.tokens[token.TokenHash] = *token
+	return nil
+}
+
+func (r *memoryUserTokens) Consume(ctx context.Context, purpose, hash string, now time.Time) (models.UserToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[hash]
+	if !ok || token.Purpose != purpose || token.UsedAt != nil || !token.ExpiresAt.After(now) {
+		return models.UserToken{}, ErrNotFound
+	}
+	token.UsedAt = &now
+	r.tokens[hash] = token
+	return token, nil
+}
+
+func (r *memoryUserTokens) InvalidateUser(ctx context.Context, userID, purpose string, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for hash, token := range r.tokens {
+		if token.UserID == userID && token.Purpose == purpose && token.UsedAt == nil {
+			token.UsedAt = &now
+			r.tokens[hash] = token
+		}
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	models "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryUsers keeps users keyed by user_id.
+type memoryUsers struct {
+	mu    sync.RWMutex
+	users map[string]models.User
+}
+
+func newMemoryUsers(seed []models.User) *memoryUsers {
+	r := &memoryUsers{users: map[string]models.User{}}
+	for _, user := range seed {
+		if user.ID.IsZero() {
+			user.ID = primitive.NewObjectID()
+		}
+		if user.UserID == "" {
+			user.UserID = user.ID.Hex()
+		}
+		r.users[user.UserID] = cloneUser(user)
+	}
+	return r
+}
+
+func cloneUser(user models.User) models.User {
+	user.FavouriteGenres = slices.Clone(user.FavouriteGenres)
+	return user
+}
+
+func (r *memoryUsers) GetByID(ctx context.Context, userID string) (models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[userID]
+	if !ok {
+		return models.User{}, ErrNotFound
+	}
+	return cloneUser(user), nil
+}
+
+func (r *memoryUsers) GetByEmail(ctx context.Context, email string) (models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if user.Email == email {
+			return cloneUser(user), nil
+		}
+	}
+	return models.User{}, ErrNotFound
+}
+
+func (r *memoryUsers) Insert(ctx context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[user.UserID]; ok {
+		return ErrDuplicate
+	}
+	for _, existing := range r.users {
+		if existing.Email == user.Email {
+			return ErrDuplicate
+		}
+	}
+	if user.ID.IsZero() {
+		user.ID = primitive.NewObjectID()
+	}
+	r.users[user.UserID] = cloneUser(*user)
+	return nil
+}
+
+func (r *memoryUsers) Update(ctx context.Context, userID string, changes UserChanges, at time.Time) (models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[userID]
+	if !ok {
+		return models.User{}, ErrNotFound
+	}
+	if changes.FirstName != nil {
+		user.FirstName = *changes.FirstName
+	}
+	if changes.LastName != nil {
+		user.LastName = *changes.LastName
+	}
+	if changes.PendingEmail != nil {
+		user.PendingEmail = *changes.PendingEmail
+	}
+	if changes.FavouriteGenres != nil {
+		user.FavouriteGenres = slices.Clone(changes.FavouriteGenres)
+	}
+	if changes.Role != nil {
+		user.Role = *changes.Role
+	}
+	if changes.Status != nil {
+		user.Status = *changes.Status
+	}
+	user.UpdatedAt = at
+	r.users[userID] = user
+	return cloneUser(user), nil
+}
+
+func (r *memoryUsers) MarkEmailVerified(ctx context.Context, userID, email string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[userID]
+	if !ok || (user.Email != email && user.PendingEmail != email) {
+		return ErrNotFound
+	}
+	if user.PendingEmail == email {
+		for _, existing := range r.users {
+			if existing.UserID != userID && existing.Email == email {
+				return ErrDuplicate
+			}
+		}
+		user.Email = email
+		user.PendingEmail = ""
+	}
+	user.EmailVerified = true
+	user.EmailVerifiedAt = &at
+	user.UpdatedAt = at
+	r.users[userID] = user
+	return nil
+}
+
+func (r *memoryUsers) SetPassword(ctx context.Context, userID, passwordHash string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[userID]
+	if !ok {
+		return ErrNotFound
+	}
+	user.Password = passwordHash
+	user.UpdatedAt = at
+	r.users[userID] = user
+	return nil
+}
+
+func (r *memoryUsers) Lock(ctx context.Context, userID string, until, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[userID]
+	if !ok {
+		return ErrNotFound
+	}
+	user.LockedUntil = &until
+	user.UpdatedAt = at
+	r.users[userID] = user
+	return nil
+}
+
+func (r *memoryUsers) Unlock(ctx context.Context, userID string, at time.Time) (models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[userID]
+	if !ok {
+		return models.User{}, ErrNotFound
+	}
+	user.LockedUntil = nil
+	user.UpdatedAt = at
+	r.users[userID] = user
+	return cloneUser(user), nil
+}
+
+func (f UserFilter) matches(user models.User) bool {
+	status := user.AccountStatus()
+	if f.Status == "" && status == models.UserDeleted {
+		return false
+	}
+	if q := strings.ToLower(f.Query); q != "" &&
+		!strings.Contains(strings.ToLower(user.Email), q) &&
+		!strings.Contains(strings.ToLower(user.FirstName), q) &&
+		!strings.Contains(strings.ToLower(user.LastName), q) {
+		return false
+	}
+	return (f.Role == "" || user.Role == f.Role) && (f.Status == "" || status == f.Status)
+}
+
+func (r *memoryUsers) Page(ctx context.Context, filter UserFilter, offset, limit int) (Page[models.User], error) {
+	r.mu.RLock()
+	users := []models.User{}
+	for _, user := range r.users {
+		if filter.matches(user) {
+			users = append(users, cloneUser(user))
+		}
+	}
+	r.mu.RUnlock()
+
+	return offsetPage(users, func(a, b models.User) int {
+		return newestFirst(a.CreatedAt, b.CreatedAt, a.ID, b.ID)
+	}, offset, limit), nil
+}
+
+func (r *memoryUsers) CountActiveAdmins(ctx context.Context) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, user := range r.users {
+		if user.Role == "ADMIN" && user.AccountStatus() == models.UserActive {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memoryUsers) Delete(ctx context.Context, userID string, anonymise bool, at time.Time) (models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[userID]
+	if !ok || user.AccountStatus() == models.UserDeleted {
+		return models.User{}, ErrNotFound
+	}
+	if anonymise {
+		replacement := anonymous(userID)
+		replacement.ID = user.ID
+		replacement.Role = user.Role
+		replacement.CreatedAt = user.CreatedAt
+		user = replacement
+	}
+	user.Status = models.UserDeleted
+	user.DeletedAt = &at
+	user.UpdatedAt = at
+	r.users[userID] = user
+	return cloneUser(user), nil
+}
+
+func (r *memoryUsers) ReplaceGenre(ctx context.Context, from int, to *models.Genre) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var changed int64
+	for userID, user := range r.users {
+		genres, ok := replaceGenre(user.FavouriteGenres, from, to)
+		if !ok {
+			continue
+		}
+		user.FavouriteGenres = genres
+		r.users[userID] = user
+		changed++
+	}
+	return changed, nil
+}
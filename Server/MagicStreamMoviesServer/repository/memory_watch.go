@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	models "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// offsetPage sorts items and cuts the page starting at offset.
+func offsetPage[T any](items []T, compare func(a, b T) int, offset, limit int) Page[T] {
+	slices.SortFunc(items, compare)
+
+	page := Page[T]{Total: int64(len(items))}
+	if offset > len(items) {
+		offset = len(items)
+	}
+	items = items[offset:]
+	if len(items) > limit {
+		items = items[:limit]
+		page.More = true
+	}
+	page.Items = items
+	return page
+}
+
+// newestFirst orders records by a timestamp, most recent first, with _id as
+// the tie-breaker.
+func newestFirst(at1, at2 time.Time, id1, id2 primitive.ObjectID) int {
+	if order := at2.Compare(at1); order != 0 {
+		return order
+	}
+	return compareObjectIDs(id2, id1)
+}
+
+type memoryWatchlist struct {
+	mu    sync.RWMutex
+	items map[watchKey]models.WatchlistItem
+}
+
+func (r *memoryWatchlist) Add(ctx context.Context, userID, imdbID string, at time.Time) (models.WatchlistItem, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := watchKey{userID, imdbID}
+	if item, ok := r.items[key]; ok {
+		return item, false, nil
+	}
+	item := models.WatchlistItem{ID: primitive.NewObjectID(), UserID: userID, ImdbID: imdbID, AddedAt: at}
+	r.items[key] = item
+	return item, true, nil
+}
+
+func (r *memoryWatchlist) Remove(ctx context.Context, userID, imdbID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := watchKey{userID, imdbID}
+	if _, ok := r.items[key]; !ok {
+		return ErrNotFound
+	}
+	delete(r.items, key)
+	return nil
+}
+
+func (r *memoryWatchlist) Page(ctx context.Context, userID string, offset, limit int) (Page[models.WatchlistItem], error) {
+	r.mu.RLock()
+	items := []models.WatchlistItem{}
+	for key, item := range r.items {
+		if key.userID == userID {
+			items = append(items, item)
+		}
+	}
+	r.mu.RUnlock()
+
+	return offsetPage(items, func(a, b models.WatchlistItem) int {
+		return newestFirst(a.AddedAt, b.AddedAt, a.ID, b.ID)
+	}, offset, limit), nil
+}
+
+type memoryHistory struct {
+	mu      sync.RWMutex
+	entries map[watchKey]models.WatchHistory
+}
+
+func (r *memoryHistory) Record(ctx context.Context, userID string, event models.PlaybackEvent, at time.Time) (models.WatchHistory, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := watchKey{userID, event.ImdbID}
+	entry, ok := r.entries[key]
+	if !ok {
+		entry = models.WatchHistory{ID: primitive.NewObjectID(), UserID: userID, ImdbID: event.ImdbID}
+	}
+	entry.SecondsWatched = event.SecondsWatched
+	entry.LastWatchedAt = at
+	// Once finished, an entry stays finished, so rewatching the opening of a
+	// movie does not move it back to "continue watching".
+	if event.Finished {
+		entry.Finished = true
+		entry.FinishedAt = &at
+	}
+	r.entries[key] = entry
+	return entry, !ok, nil
+}
+
+func (r *memoryHistory) Page(ctx context.Context, userID string, finished *bool, offset, limit int) (Page[models.WatchHistory], error) {
+	r.mu.RLock()
+	entries := []models.WatchHistory{}
+	for key, entry := range r.entries {
+		if key.userID == userID && (finished == nil || entry.Finished == *finished) {
+			entries = append(entries, entry)
+		}
+	}
+	r.mu.RUnlock()
+
+	return offsetPage(entries, func(a, b models.WatchHistory) int {
+		return newestFirst(a.LastWatchedAt, b.LastWatchedAt, a.ID, b.ID)
+	}, offset, limit), nil
+}
+
+func (r *memoryHistory) FinishedImdbIDs(ctx context.Context, userID string) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := []string{}
+	for key, entry := range r.entries {
+		if key.userID == userID && entry.Finished {
+			ids = append(ids, key.imdbID)
+		}
+	}
+	return ids, nil
+}
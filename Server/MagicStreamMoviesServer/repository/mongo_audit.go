@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+
+	models "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type mongoAudit struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoAudit) Record(ctx context.Context, entry models.AuditEntry) error {
+	_, err := r.collection.InsertOne(ctx, entry)
+	return err
+}
+
+func (r *mongoAudit) Page(ctx context.Context, filter AuditFilter, offset, limit int) (Page[models.AuditEntry], error) {
+	query := bson.M{}
+	if filter.ActorID != "" {
+		query["actor_id"] = filter.ActorID
+	}
+	if filter.TargetID != "" {
+		query["target_id"] = filter.TargetID
+	}
+	return findOffsetPage[models.AuditEntry](ctx, r.collection, query, bson.D{{Key: "at", Value: -1}, {Key: "_id", Value: -1}}, offset, limit)
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+
+	models "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoGenres struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoGenres) List(ctx context.Context) ([]models.Genre, error) {
+	sort := options.Find().SetSort(bson.D{{Key: "genre_id", Value: 1}})
+	return findAll[models.Genre](ctx, r.collection, bson.M{}, sort)
+}
+
+func (r *mongoGenres) Get(ctx context.Context, genreID int) (models.Genre, error) {
+	var genre models.Genre
+	err := r.collection.FindOne(ctx, bson.M{"genre_id": genreID}).Decode(&genre)
+	return genre, notFound(err)
+}
+
+func (r *mongoGenres) Insert(ctx context.Context, genre models.Genre) error {
+	// The unique genre_id index added by migration 3 rejects duplicates
+	_, err := r.collection.InsertOne(ctx, genre)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+func (r *mongoGenres) Rename(ctx context.Context, genreID int, name string) (models.Genre, error) {
+	var genre models.Genre
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"genre_id": genreID}, bson.M{"$set": bson.M{"genre_name": name}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&genre)
+	return genre, notFound(err)
+}
+
+func (r *mongoGenres) Delete(ctx context.Context, genreID int) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"genre_id": genreID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// replaceGenreMany rewrites the genre list in field of the documents listing
+// the genre with ID from, as described by MovieRepository.ReplaceGenre, and
+// returns how many changed.
+func replaceGenreMany(ctx context.Context, collection *mongo.Collection, field string, from int, to *models.Genre) (int64, error) {
+	list := "$" + field
+	if to == nil {
+		list = "$$kept"
+	}
+	// Swap from for to in place, then drop any later entry whose ID is
+	// already listed
+	pipeline := mongo.Pipeline{{{Key: "$set", Value: bson.M{field: bson.M{
+		"$let": bson.M{
+			"vars": bson.M{"kept": bson.M{"$filter": bson.M{
+				"input": "$" + field,
+				"cond":  bson.M{"$ne": bson.A{"$$this.genre_id", from}},
+			}}},
+			"in": bson.M{"$reduce": bson.M{
+				"input": bson.M{"$map": bson.M{
+					"input": list,
+					"in":    bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$$this.genre_id", from}}, bson.M{"$literal": to}, "$$this"}},
+				}},
+				"initialValue": bson.A{},
+				"in": bson.M{"$cond": bson.A{
+					bson.M{"$in": bson.A{"$$this.genre_id", "$$value.genre_id"}},
+					"$$value",
+					bson.M{"$concatArrays": bson.A{"$$value", bson.A{"$$this"}}},
+				}},
+			}},
+		},
+	}}}}}
+
+	result, err := collection.UpdateMany(ctx, bson.M{field + ".genre_id": from}, pipeline)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+type mongoRankings struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoRankings) List(ctx context.Context) ([]models.Ranking, error) {
+	sort := options.Find().SetSort(bson.D{{Key: "ranking_value", Value: 1}})
+	return findAll[models.Ranking](ctx, r.collection, bson.M{}, sort)
+}
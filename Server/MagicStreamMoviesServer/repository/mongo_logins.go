@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	models "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoLogins struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoLogins) Get(ctx context.Context, key string) (models.LoginAttempt, error) {
+	var attempt models.LoginAttempt
+	// The TTL monitor only runs once a minute, so expired counters can linger
+	err := r.collection.FindOne(ctx, bson.M{"_id": key, "expires_at": bson.M{"$gt": time.Now()}}).Decode(&attempt)
+	return attempt, notFound(err)
+}
+
+// RecordFailure counts the failure in one upserting pipeline update, so
+// concurrent failures from a password-guessing script are all counted.
+func (r *mongoLogins) RecordFailure(ctx context.Context, key string, at, forgetBefore, expiresAt time.Time) (models.LoginAttempt, error) {
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"failures": bson.M{"$cond": bson.A{
+			bson.M{"$gt": bson.A{"$last_failure_at", forgetBefore}},
+			bson.M{"$add": bson.A{"$failures", 1}},
+			1,
+		}},
+		"last_failure_at": at,
+		"expires_at":      expiresAt,
+	}}}}
+	var attempt models.LoginAttempt
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": key}, update,
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).Decode(&attempt)
+	return attempt, err
+}
+
+func (r *mongoLogins) Reset(ctx context.Context, key string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": key})
+	return err
+}
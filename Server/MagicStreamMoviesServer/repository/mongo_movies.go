@@ -0,0 +1,307 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	models "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const textIndexCheckInterval = time.Minute
+
+// mongoSortFields maps each SortField to the document field it orders by.
+var mongoSortFields = map[SortField]string{
+	SortByTitle:   "title",
+	SortByRanking: "ranking.ranking_value",
+	SortByCreated: "_id",
+}
+
+type mongoMovies struct {
+	collection *mongo.Collection
+	revision   atomic.Uint64
+
+	// textIndex caches whether the collection has a text index so the index
+	// list is not fetched on every search.
+	textIndex struct {
+		sync.Mutex
+		present   bool
+		checkedAt time.Time
+	}
+}
+
+// activeMovies restricts a movie filter to movies that have not been soft
+// deleted.
+func activeMovies(filter bson.M) bson.M {
+	filter["deleted_at"] = nil
+	return filter
+}
+
+func movieFilter(f MovieFilter) bson.M {
+	filter := activeMovies(bson.M{})
+	if len(f.GenreIDs) > 0 {
+		filter["genre.genre_id"] = bson.M{"$in": f.GenreIDs}
+	}
+	if f.MinRanking != nil || f.MaxRanking != nil {
+		rankingRange := bson.M{}
+		if f.MinRanking != nil {
+			rankingRange["$gte"] = *f.MinRanking
+		}
+		if f.MaxRanking != nil {
+			rankingRange["$lte"] = *f.MaxRanking
+		}
+		filter["ranking.ranking_value"] = rankingRange
+	}
+	// An anchored, case-sensitive regex lets Mongo walk the title index.
+	if f.TitlePrefix != "" {
+		filter["title"] = bson.M{"$regex": "^" + regexp.QuoteMeta(f.TitlePrefix)}
+	}
+	if len(f.ExcludeImdbIDs) > 0 {
+		filter["imdb_id"] = bson.M{"$nin": f.ExcludeImdbIDs}
+	}
+	return filter
+}
+
+func movieSort(s MovieSort) bson.D {
+	direction := 1
+	if s.Descending {
+		direction = -1
+	}
+	field := mongoSortFields[s.Field]
+	if field == "" || field == "_id" {
+		return bson.D{{Key: "_id", Value: direction}}
+	}
+	return bson.D{{Key: field, Value: direction}, {Key: "_id", Value: direction}}
+}
+
+// afterCursor builds the keyset condition that selects documents strictly
+// after the cursor position, using _id as the tie-breaker.
+func afterCursor(s MovieSort, cursor *Cursor) bson.M {
+	op := "$gt"
+	if s.Descending {
+		op = "$lt"
+	}
+	field := mongoSortFields[s.Field]
+	if field == "" || field == "_id" {
+		return bson.M{"_id": bson.M{op: cursor.ID}}
+	}
+	return bson.M{"$or": bson.A{
+		bson.M{field: bson.M{op: cursor.Value}},
+		bson.M{field: cursor.Value, "_id": bson.M{op: cursor.ID}},
+	}}
+}
+
+func (r *mongoMovies) Revision() uint64 {
+	return r.revision.Load()
+}
+
+func (r *mongoMovies) changed() {
+	r.revision.Add(1)
+}
+
+func (r *mongoMovies) Page(ctx context.Context, q MovieQuery) (Page[models.Movie], error) {
+	var page Page[models.Movie]
+
+	filter := movieFilter(q.Filter)
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return page, err
+	}
+	page.Total = total
+
+	if q.After != nil {
+		filter = bson.M{"$and": bson.A{filter, afterCursor(q.Sort, q.After)}}
+	}
+
+	// Fetch one extra document to learn whether another page exists.
+	findOptions := options.Find().SetSort(movieSort(q.Sort)).SetLimit(int64(q.Limit + 1))
+	page.Items, err = findAll[models.Movie](ctx, r.collection, filter, findOptions)
+	if err != nil {
+		return page, err
+	}
+	if len(page.Items) > q.Limit {
+		page.Items = page.Items[:q.Limit]
+		page.More = true
+	}
+	return page, nil
+}
+
+func (r *mongoMovies) Find(ctx context.Context, filter MovieFilter, sort MovieSort, limit int) ([]models.Movie, error) {
+	findOptions := options.Find().SetSort(movieSort(sort))
+	if limit > 0 {
+		findOptions.SetLimit(int64(limit))
+	}
+	return findAll[models.Movie](ctx, r.collection, movieFilter(filter), findOptions)
+}
+
+func (r *mongoMovies) FindByImdbIDs(ctx context.Context, ids []string) ([]models.Movie, error) {
+	return findAll[models.Movie](ctx, r.collection, activeMovies(bson.M{"imdb_id": bson.M{"$in": ids}}))
+}
+
+func (r *mongoMovies) Get(ctx context.Context, imdbID string) (models.Movie, error) {
+	var movie models.Movie
+	err := r.collection.FindOne(ctx, activeMovies(bson.M{"imdb_id": imdbID})).Decode(&movie)
+	return movie, notFound(err)
+}
+
+func (r *mongoMovies) Exists(ctx context.Context, imdbID string) (bool, error) {
+	count, err := r.collection.CountDocuments(ctx, activeMovies(bson.M{"imdb_id": imdbID}))
+	return count > 0, err
+}
+
+func (r *mongoMovies) hasTextIndex(ctx context.Context) bool {
+	r.textIndex.Lock()
+	defer r.textIndex.Unlock()
+
+	if time.Since(r.textIndex.checkedAt) < textIndexCheckInterval {
+		return r.textIndex.present
+	}
+
+	present := false
+	specs, err := r.collection.Indexes().ListSpecifications(ctx)
+	if err == nil {
+		for _, spec := range specs {
+			if _, ok := spec.KeysDocument.Lookup("_fts").StringValueOK(); ok {
+				present = true
+				break
+			}
+		}
+	}
+
+	r.textIndex.present = present
+	r.textIndex.checkedAt = time.Now()
+	return present
+}
+
+// TextSearch delegates matching and scoring to the Mongo text index added by
+// migration 14, which is already case and diacritic insensitive.
+func (r *mongoMovies) TextSearch(ctx context.Context, q string, offset, limit int) ([]models.MovieSearchResult, int64, error) {
+	if !r.hasTextIndex(ctx) {
+		return nil, 0, ErrTextSearchUnsupported
+	}
+
+	filter := activeMovies(bson.M{"$text": bson.M{
+		"$search":             q,
+		"$caseSensitive":      false,
+		"$diacriticSensitive": false,
+	}})
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	findOptions := options.Find().
+		SetProjection(bson.M{"score": bson.M{"$meta": "textScore"}}).
+		SetSort(bson.D{
+			{Key: "score", Value: bson.M{"$meta": "textScore"}},
+			{Key: "_id", Value: 1},
+		}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+
+	results, err := findAll[models.MovieSearchResult](ctx, r.collection, filter, findOptions)
+	if err != nil {
+		return nil, 0, err
+	}
+	return results, total, nil
+}
+
+func (r *mongoMovies) Insert(ctx context.Context, movie *models.Movie) error {
+	// The unique imdb_id index added by migration 2 rejects duplicates,
+	// including the imdb_id of a deleted movie that can still be restored
+	result, err := r.collection.InsertOne(ctx, movie)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	if err != nil {
+		return err
+	}
+	if id, ok := result.InsertedID.(primitive.ObjectID); ok {
+		movie.ID = id
+	}
+	r.changed()
+	return nil
+}
+
+// update applies update to the movie matching filter and returns the result.
+func (r *mongoMovies) update(ctx context.Context, filter, update bson.M) (models.Movie, error) {
+	var updated models.Movie
+	err := r.collection.FindOneAndUpdate(ctx, filter, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err != nil {
+		return updated, notFound(err)
+	}
+	r.changed()
+	return updated, nil
+}
+
+func (r *mongoMovies) Replace(ctx context.Context, imdbID string, movie models.Movie) (models.Movie, error) {
+	return r.update(ctx, activeMovies(bson.M{"imdb_id": imdbID}), bson.M{"$set": EditableMovieFields(movie)})
+}
+
+func (r *mongoMovies) Upsert(ctx context.Context, movie models.Movie) (bool, error) {
+	update := bson.M{
+		"$set":         EditableMovieFields(movie),
+		"$setOnInsert": bson.M{"audience_score": 0.0, "rating_count": 0},
+	}
+	// Only an active movie matches, so for a deleted one the upsert tries to
+	// insert and the unique imdb_id index rejects it
+	result, err := r.collection.UpdateOne(ctx, activeMovies(bson.M{"imdb_id": movie.ImdbID}), update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, ErrDeleted
+	}
+	if err != nil {
+		return false, err
+	}
+	r.changed()
+	return result.UpsertedCount > 0, nil
+}
+
+func (r *mongoMovies) SetReview(ctx context.Context, imdbID, review string, ranking models.Ranking) (models.Movie, error) {
+	return r.update(ctx, activeMovies(bson.M{"imdb_id": imdbID}), bson.M{"$set": bson.M{
+		"admin_review": review,
+		"ranking":      ranking,
+	}})
+}
+
+func (r *mongoMovies) SoftDelete(ctx context.Context, imdbID string, at time.Time) error {
+	result, err := r.collection.UpdateOne(ctx,
+		activeMovies(bson.M{"imdb_id": imdbID}),
+		bson.M{"$set": bson.M{"deleted_at": at}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	r.changed()
+	return nil
+}
+
+func (r *mongoMovies) Restore(ctx context.Context, imdbID string) (models.Movie, error) {
+	return r.update(ctx,
+		bson.M{"imdb_id": imdbID, "deleted_at": bson.M{"$ne": nil}},
+		bson.M{"$unset": bson.M{"deleted_at": ""}},
+	)
+}
+
+func (r *mongoMovies) CountWithGenre(ctx context.Context, genreID int) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{"genre.genre_id": genreID})
+}
+
+func (r *mongoMovies) ReplaceGenre(ctx context.Context, from int, to *models.Genre) (int64, error) {
+	changed, err := replaceGenreMany(ctx, r.collection, "genre", from, to)
+	if changed > 0 {
+		r.changed()
+	}
+	return changed, err
+}
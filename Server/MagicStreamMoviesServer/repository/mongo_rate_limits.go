@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	models "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoRateLimits struct {
+	collection *mongo.Collection
+}
+
+// Take runs the refill and take of memoryRateLimits as one upserting
+// pipeline update, so concurrent requests on any instance each see the
+// tokens the others left. Two instances creating the same bucket at once
+// race on _id; the loser retries and updates the winner's bucket.
+func (r *mongoRateLimits) Take(ctx context.Context, key string, capacity int, rate float64, now time.Time) (models.RateLimitBucket, error) {
+	// updated_at is missing on a new bucket, so no time has elapsed and
+	// tokens defaults to a full bucket.
+	elapsed := bson.M{"$divide": bson.A{
+		bson.M{"$max": bson.A{bson.M{"$subtract": bson.A{now, bson.M{"$ifNull": bson.A{"$updated_at", now}}}}, 0}},
+		1000,
+	}}
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"tokens": bson.M{"$min": bson.A{
+				capacity,
+				bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$tokens", capacity}}, bson.M{"$multiply": bson.A{elapsed, rate}}}},
+			}},
+		}}},
+		{{Key: "$set", Value: bson.M{"allowed": bson.M{"$gte": bson.A{"$tokens", 1}}}}},
+		{{Key: "$set", Value: bson.M{
+			"tokens":     bson.M{"$cond": bson.A{"$allowed", bson.M{"$subtract": bson.A{"$tokens", 1}}, "$tokens"}},
+			"updated_at": now,
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"expires_at": bson.M{"$add": bson.A{now, bson.M{"$multiply": bson.A{
+				bson.M{"$divide": bson.A{bson.M{"$subtract": bson.A{capacity, "$tokens"}}, rate}},
+				1000,
+			}}}},
+		}}},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var bucket models.RateLimitBucket
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": key}, update, opts).Decode(&bucket)
+	if mongo.IsDuplicateKeyError(err) {
+		err = r.collection.FindOneAndUpdate(ctx, bson.M{"_id": key}, update, opts).Decode(&bucket)
+	}
+	return bucket, err
+}
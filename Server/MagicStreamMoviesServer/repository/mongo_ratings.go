@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+
+	models "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoRatings struct {
+	collection *mongo.Collection
+}
+
+func ratingFilter(f RatingFilter) bson.M {
+	filter := bson.M{}
+	if f.UserID != "" {
+		filter["user_id"] = f.UserID
+	}
+	if f.ImdbID != "" {
+		filter["imdb_id"] = f.ImdbID
+	}
+	return filter
+}
+
+func (r *mongoRatings) Upsert(ctx context.Context, rating models.Rating) (models.Rating, bool, error) {
+	filter := bson.M{"user_id": rating.UserID, "imdb_id": rating.ImdbID}
+	update := bson.M{
+		"$set": bson.M{
+			"stars":      rating.Stars,
+			"review":     rating.Review,
+			"updated_at": rating.UpdatedAt,
+		},
+		"$setOnInsert": bson.M{"created_at": rating.UpdatedAt},
+	}
+	result, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		// A concurrent first rating by the same user inserted the document
+		// between our match and insert, so this time it updates it
+		result, err = r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	}
+	if err != nil {
+		return rating, false, err
+	}
+
+	var saved models.Rating
+	if err = r.collection.FindOne(ctx, filter).Decode(&saved); err != nil {
+		return rating, false, err
+	}
+	return saved, result.UpsertedCount > 0, nil
+}
+
+func (r *mongoRatings) Delete(ctx context.Context, userID, imdbID string) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"user_id": userID, "imdb_id": imdbID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoRatings) Page(ctx context.Context, f RatingFilter, after primitive.ObjectID, limit int) (Page[models.Rating], error) {
+	var page Page[models.Rating]
+
+	filter := ratingFilter(f)
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return page, err
+	}
+	page.Total = total
+
+	if !after.IsZero() {
+		filter["_id"] = bson.M{"$lt": after}
+	}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(int64(limit + 1))
+	page.Items, err = findAll[models.Rating](ctx, r.collection, filter, findOptions)
+	if err != nil {
+		return page, err
+	}
+	if len(page.Items) > limit {
+		page.Items = page.Items[:limit]
+		page.More = true
+	}
+	return page, nil
+}
+
+// SyncAudienceScore runs one aggregation that reads the movie's ratings and
+// merges the result into the movie document, so the score and count always
+// come from the same set of ratings. Two syncs racing rating changes can
+// still finish in either order, leaving the movie a rating behind until the
+// next change syncs it again.
+func (r *mongoRatings) SyncAudienceScore(ctx context.Context, imdbID string) error {
+	movies := r.collection.Database().Collection("movies")
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"imdb_id": imdbID}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from": r.collection.Name(),
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{"imdb_id": imdbID}},
+				bson.M{"$group": bson.M{
+					"_id":   nil,
+					"score": bson.M{"$avg": "$stars"},
+					"count": bson.M{"$sum": 1},
+				}},
+			},
+			"as": "summary",
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"audience_score": bson.M{"$round": bson.A{
+				bson.M{"$ifNull": bson.A{bson.M{"$arrayElemAt": bson.A{"$summary.score", 0}}, 0}},
+				2,
+			}},
+			"rating_count": bson.M{"$ifNull": bson.A{bson.M{"$arrayElemAt": bson.A{"$summary.count", 0}}, 0}},
+		}}},
+		{{Key: "$merge", Value: bson.M{
+			"into":           movies.Name(),
+			"on":             "_id",
+			"whenMatched":    "merge",
+			"whenNotMatched": "discard",
+		}}},
+	}
+	cursor, err := movies.Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	return cursor.Close(ctx)
+}
+
+func (r *mongoRatings) RatedImdbIDs(ctx context.Context, userID string) ([]string, error) {
+	return imdbIDs(ctx, r.collection, bson.M{"user_id": userID})
+}
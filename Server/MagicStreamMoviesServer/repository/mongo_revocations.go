@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	models "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type mongoRevocations struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoRevocations) Revoke(ctx context.Context, revocation models.Revocation) error {
+	_, err := r.collection.InsertOne(ctx, revocation)
+	return err
+}
+
+func (r *mongoRevocations) IsRevoked(ctx context.Context, tokenID, sessionID, userID string, issuedAt time.Time) (bool, error) {
+	or := bson.A{bson.M{"user_id": userID, "issued_before": bson.M{"$gt": issuedAt}}}
+	if sessionID != "" {
+		or = append(or, bson.M{"user_id": userID, "session_ids": sessionID})
+	}
+	if tokenID != "" {
+		or = append(or, bson.M{"jti": tokenID})
+	}
+	if sessionID != "" {
+		or = append(or, bson.M{"session_id": sessionID})
+	}
+
+	// The TTL monitor only runs once a minute, so expired entries are
+	// filtered out here as well.
+	count, err := r.collection.CountDocuments(ctx, bson.M{
+		"$or":        or,
+		"expires_at": bson.M{"$gt": time.Now()},
+	})
+	return count > 0, err
+}
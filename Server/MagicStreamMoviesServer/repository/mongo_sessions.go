@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	models "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoSessions struct {
+	collection *mongo.Collection
+}
+
+// activeSessions matches unrevoked sessions; expired ones are left to the
+// TTL index.
+func activeSessions(filter bson.M) bson.M {
+	filter["revoked_at"] = bson.M{"$exists": false}
+	return filter
+}
+
+func revoke(reason string, at time.Time) bson.M {
+	return bson.M{"$set": bson.M{"revoked_at": at, "revoked_reason": reason}}
+}
+
+func (r *mongoSessions) Create(ctx context.Context, session *models.Session) error {
+	result, err := r.collection.InsertOne(ctx, session)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	if err != nil {
+		return err
+	}
+	if id, ok := result.InsertedID.(primitive.ObjectID); ok {
+		session.ID = id
+	}
+	return nil
+}
+
+func (r *mongoSessions) GetByTokenHash(ctx context.Context, hash string) (models.Session, error) {
+	var session models.Session
+	err := r.collection.FindOne(ctx, bson.M{"refresh_token_hash": hash}).Decode(&session)
+	return session, notFound(err)
+}
+
+func (r *mongoSessions) Rotate(ctx context.Context, current primitive.ObjectID, next *models.Session, at time.Time) error {
+	result, err := r.collection.UpdateOne(ctx, activeSessions(bson.M{"_id": current}), revoke(models.SessionRotated, at))
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return r.Create(ctx, next)
+}
+
+func (r *mongoSessions) RevokeFamily(ctx context.Context, userID, familyID, reason string, at time.Time) error {
+	result, err := r.collection.UpdateMany(ctx, activeSessions(bson.M{"user_id": userID, "family_id": familyID}), revoke(reason, at))
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoSessions) RevokeUser(ctx context.Context, userID, reason string, at time.Time) ([]string, error) {
+	// The sessions are listed first so that exactly the ones listed are
+	// revoked, even if the user signs in again meanwhile
+	active, err := findAll[models.Session](ctx, r.collection, activeSessions(bson.M{"user_id": userID}),
+		options.Find().SetProjection(bson.M{"family_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]primitive.ObjectID, len(active))
+	families := make([]string, len(active))
+	for i, session := range active {
+		ids[i] = session.ID
+		families[i] = session.FamilyID
+	}
+	if len(ids) == 0 {
+		return families, nil
+	}
+
+	_, err = r.collection.UpdateMany(ctx, activeSessions(bson.M{"_id": bson.M{"$in": ids}}), revoke(reason, at))
+	if err != nil {
+		return nil, err
+	}
+	return families, nil
+}
+
+func (r *mongoSessions) ListActive(ctx context.Context, userID string, now time.Time) ([]models.Session, error) {
+	filter := activeSessions(bson.M{"user_id": userID, "expires_at": bson.M{"$gt": now}})
+	return findAll[models.Session](ctx, r.collection, filter,
+		options.Find().SetSort(bson.D{{Key: "last_used_at", Value: -1}, {Key: "_id", Value: -1}}))
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	database "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// NewMongoStore returns repositories backed by the collections of the
+// given database.
+func NewMongoStore(db *mongo.Database) *Store {
+	return &Store{
+		Movies:      &mongoMovies{collection: database.OpenCollection("movies", db)},
+		Users:       &mongoUsers{collection: database.OpenCollection("users", db)},
+		Sessions:    &mongoSessions{collection: database.OpenCollection("sessions", db)},
+		Revocations: &mongoRevocations{collection: database.OpenCollection("revocations", db)},
+		UserTokens:  &mongoUserTokens{collection: database.OpenCollection("user_tokens", db)},
+		Logins:      &mongoLogins{collection: database.OpenCollection("login_attempts", db)},
+		RateLimits:  &mongoRateLimits{collection: database.OpenCollection("rate_limits", db)},
+		Audit:       &mongoAudit{collection: database.OpenCollection("audit_log", db)},
+		Genres:      &mongoGenres{collection: database.OpenCollection("genres", db)},
+		Rankings:    &mongoRankings{collection: database.OpenCollection("rankings", db)},
+		Ratings:     &mongoRatings{collection: database.OpenCollection("ratings", db)},
+		Watchlist:   &mongoWatchlist{collection: database.OpenCollection("watchlist", db)},
+		History:     &mongoHistory{collection: database.OpenCollection("watch_history", db)},
+	}
+}
+
+// notFound maps the driver's "no documents" error onto ErrNotFound.
+func notFound(err error) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// findAll runs a query and decodes every result, never returning nil.
+func findAll[T any](ctx context.Context, collection *mongo.Collection, filter interface{}, opts ...*options.FindOptions) ([]T, error) {
+	cursor, err := collection.Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	items := []T{}
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// findOffsetPage runs an offset-paginated query.
+func findOffsetPage[T any](ctx context.Context, collection *mongo.Collection, filter bson.M, sort bson.D, offset, limit int) (Page[T], error) {
+	var page Page[T]
+
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return page, err
+	}
+	page.Total = total
+
+	findOptions := options.Find().SetSort(sort).SetSkip(int64(offset)).SetLimit(int64(limit))
+	page.Items, err = findAll[T](ctx, collection, filter, findOptions)
+	if err != nil {
+		return page, err
+	}
+	page.More = int64(offset+limit) < total
+	return page, nil
+}
+
+// imdbIDs projects the imdb_id of every document matching filter.
+func imdbIDs(ctx context.Context, collection *mongo.Collection, filter bson.M) ([]string, error) {
+	docs, err := findAll[struct {
+		ImdbID string `bson:"imdb_id"`
+	}](ctx, collection, filter, options.Find().SetProjection(bson.M{"imdb_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc.ImdbID
+	}
+	return ids, nil
+}
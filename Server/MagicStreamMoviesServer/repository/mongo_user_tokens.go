@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	models "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoUserTokens struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoUserTokens) Create(ctx context.Context, token *models.UserToken) error {
+	result, err := r.collection.InsertOne(ctx, token)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	if err != nil {
+		return err
+	}
+	if id, ok := result.InsertedID.(primitive.ObjectID); ok {
+		token.ID = id
+	}
+	return nil
+}
+
+// Consume claims the token with a single findOneAndUpdate, so two requests
+// racing with the same token cannot both use it.
+func (r *mongoUserTokens) Consume(ctx context.Context, purpose, hash string, now time.Time) (models.UserToken, error) {
+	filter := bson.M{
+		"token_hash": hash,
+		"purpose":    purpose,
+		"used_at":    bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": now},
+	}
+	var token models.UserToken
+	err := r.collection.FindOneAndUpdate(ctx, filter, bson.M{"$set": bson.M{"used_at": now}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&token)
+	return token, notFound(err)
+}
+
+func (r *mongoUserTokens) InvalidateUser(ctx context.Context, userID, purpose string, now time.Time) error {
+	_, err := r.collection.UpdateMany(ctx,
+		bson.M{"user_id": userID, "purpose": purpose, "used_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"used_at": now}})
+	return err
+}
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"time"
+
+	models "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoUsers struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoUsers) findOne(ctx context.Context, filter bson.M) (models.User, error) {
+	var user models.User
+	err := r.collection.FindOne(ctx, filter).Decode(&user)
+	return user, notFound(err)
+}
+
+func (r *mongoUsers) GetByID(ctx context.Context, userID string) (models.User, error) {
+	return r.findOne(ctx, bson.M{"user_id": userID})
+}
+
+func (r *mongoUsers) GetByEmail(ctx context.Context, email string) (models.User, error) {
+	return r.findOne(ctx, bson.M{"email": email})
+}
+
+func (r *mongoUsers) Insert(ctx context.Context, user *models.User) error {
+	// The unique email index added by migration 1 rejects duplicates
+	result, err := r.collection.InsertOne(ctx, user)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	if err != nil {
+		return err
+	}
+	if id, ok := result.InsertedID.(primitive.ObjectID); ok {
+		user.ID = id
+	}
+	return nil
+}
+
+// update sets fields on the user matching filter, returning ErrNotFound when
+// there is none.
+func (r *mongoUsers) update(ctx context.Context, filter, set bson.M) error {
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoUsers) Update(ctx context.Context, userID string, changes UserChanges, at time.Time) (models.User, error) {
+	set := bson.M{"update_at": at}
+	if changes.FirstName != nil {
+		set["first_name"] = *changes.FirstName
+	}
+	if changes.LastName != nil {
+		set["last_name"] = *changes.LastName
+	}
+	if changes.PendingEmail != nil {
+		set["pending_email"] = *changes.PendingEmail
+	}
+	if changes.FavouriteGenres != nil {
+		set["favourite_genres"] = changes.FavouriteGenres
+	}
+	if changes.Role != nil {
+		set["role"] = *changes.Role
+	}
+	if changes.Status != nil {
+		set["status"] = *changes.Status
+	}
+
+	var user models.User
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"user_id": userID}, bson.M{"$set": set},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&user)
+	return user, notFound(err)
+}
+
+func (r *mongoUsers) MarkEmailVerified(ctx context.Context, userID, email string, at time.Time) error {
+	verified := bson.M{"email_verified": true, "email_verified_at": at, "update_at": at}
+	err := r.update(ctx, bson.M{"user_id": userID, "email": email}, verified)
+	if !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	// Switching to the pending address
+	verified["email"] = email
+	result, err := r.collection.UpdateOne(ctx, bson.M{"user_id": userID, "pending_email": email},
+		bson.M{"$set": verified, "$unset": bson.M{"pending_email": ""}})
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoUsers) SetPassword(ctx context.Context, userID, passwordHash string, at time.Time) error {
+	return r.update(ctx, bson.M{"user_id": userID}, bson.M{"password": passwordHash, "update_at": at})
+}
+
+func (r *mongoUsers) Lock(ctx context.Context, userID string, until, at time.Time) error {
+	return r.update(ctx, bson.M{"user_id": userID}, bson.M{"locked_until": until, "update_at": at})
+}
+
+func (r *mongoUsers) Unlock(ctx context.Context, userID string, at time.Time) (models.User, error) {
+	var user models.User
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"user_id": userID},
+		bson.M{"$unset": bson.M{"locked_until": ""}, "$set": bson.M{"update_at": at}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&user)
+	return user, notFound(err)
+}
+
+// notDeleted matches users that have not been deleted, including those
+// stored before statuses existed.
+var notDeleted = bson.M{"$ne": models.UserDeleted}
+
+func (r *mongoUsers) Page(ctx context.Context, filter UserFilter, offset, limit int) (Page[models.User], error) {
+	query := bson.M{"status": notDeleted}
+	if filter.Query != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(filter.Query), Options: "i"}
+		query["$or"] = bson.A{
+			bson.M{"email": pattern},
+			bson.M{"first_name": pattern},
+			bson.M{"last_name": pattern},
+		}
+	}
+	if filter.Role != "" {
+		query["role"] = filter.Role
+	}
+	switch filter.Status {
+	case "":
+	case models.UserActive:
+		query["status"] = bson.M{"$nin": bson.A{models.UserDisabled, models.UserDeleted}}
+	default:
+		query["status"] = filter.Status
+	}
+	return findOffsetPage[models.User](ctx, r.collection, query, bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}, offset, limit)
+}
+
+func (r *mongoUsers) CountActiveAdmins(ctx context.Context) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{
+		"role":   "ADMIN",
+		"status": bson.M{"$nin": bson.A{models.UserDisabled, models.UserDeleted}},
+	})
+}
+
+func (r *mongoUsers) Delete(ctx context.Context, userID string, anonymise bool, at time.Time) (models.User, error) {
+	update := bson.M{"$set": bson.M{"status": models.UserDeleted, "deleted_at": at, "update_at": at}}
+	if anonymise {
+		replacement := anonymous(userID)
+		set := update["$set"].(bson.M)
+		set["first_name"] = replacement.FirstName
+		set["last_name"] = replacement.LastName
+		set["email"] = replacement.Email
+		set["password"] = ""
+		set["favourite_genres"] = replacement.FavouriteGenres
+		set["email_verified"] = false
+		update["$unset"] = bson.M{"pending_email": "", "email_verified_at": "", "locked_until": ""}
+	}
+
+	var user models.User
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"user_id": userID, "status": notDeleted}, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&user)
+	return user, notFound(err)
+}
+
+func (r *mongoUsers) ReplaceGenre(ctx context.Context, from int, to *models.Genre) (int64, error) {
+	return replaceGenreMany(ctx, r.collection, "favourite_genres", from, to)
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	models "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoWatchlist struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoWatchlist) Add(ctx context.Context, userID, imdbID string, at time.Time) (models.WatchlistItem, bool, error) {
+	var item models.WatchlistItem
+
+	filter := bson.M{"user_id": userID, "imdb_id": imdbID}
+	update := bson.M{"$setOnInsert": bson.M{"added_at": at}}
+	result, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return item, false, err
+	}
+
+	if err = r.collection.FindOne(ctx, filter).Decode(&item); err != nil {
+		return item, false, err
+	}
+	return item, result.UpsertedCount > 0, nil
+}
+
+func (r *mongoWatchlist) Remove(ctx context.Context, userID, imdbID string) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"user_id": userID, "imdb_id": imdbID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoWatchlist) Page(ctx context.Context, userID string, offset, limit int) (Page[models.WatchlistItem], error) {
+	sort := bson.D{{Key: "added_at", Value: -1}, {Key: "_id", Value: -1}}
+	return findOffsetPage[models.WatchlistItem](ctx, r.collection, bson.M{"user_id": userID}, sort, offset, limit)
+}
+
+type mongoHistory struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoHistory) Record(ctx context.Context, userID string, event models.PlaybackEvent, at time.Time) (models.WatchHistory, bool, error) {
+	var entry models.WatchHistory
+
+	filter := bson.M{"user_id": userID, "imdb_id": event.ImdbID}
+	set := bson.M{
+		"seconds_watched": event.SecondsWatched,
+		"last_watched_at": at,
+	}
+	if event.Finished {
+		set["finished_at"] = at
+	}
+	// $max keeps finished true once it has been set, so rewatching the
+	// opening of a movie does not move it back to "continue watching".
+	update := bson.M{
+		"$set": set,
+		"$max": bson.M{"finished": event.Finished},
+	}
+	result, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return entry, false, err
+	}
+
+	if err = r.collection.FindOne(ctx, filter).Decode(&entry); err != nil {
+		return entry, false, err
+	}
+	return entry, result.UpsertedCount > 0, nil
+}
+
+func (r *mongoHistory) Page(ctx context.Context, userID string, finished *bool, offset, limit int) (Page[models.WatchHistory], error) {
+	filter := bson.M{"user_id": userID}
+	if finished != nil {
+		filter["finished"] = *finished
+	}
+	sort := bson.D{{Key: "last_watched_at", Value: -1}, {Key: "_id", Value: -1}}
+	return findOffsetPage[models.WatchHistory](ctx, r.collection, filter, sort, offset, limit)
+}
+
+func (r *mongoHistory) FinishedImdbIDs(ctx context.Context, userID string) ([]string, error) {
+	return imdbIDs(ctx, r.collection, bson.M{"user_id": userID, "finished": true})
+}
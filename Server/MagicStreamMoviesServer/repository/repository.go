@@ -0,0 +1,353 @@
+// Package repository hides the storage behind the handlers. Each collection
+// is reached through an interface with a MongoDB implementation for
+// production and an in-memory one for tests and local development.
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	models "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	// ErrNotFound is returned when the requested record does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrDuplicate is returned when a write would break a uniqueness rule,
+	// such as a second movie with the same imdb_id.
+	ErrDuplicate = errors.New("duplicate record")
+	// ErrDeleted is returned by MovieRepository.Upsert for a movie that is
+	// soft deleted, which has to be restored before it can be written.
+	ErrDeleted = errors.New("record is deleted")
+	// ErrTextSearchUnsupported is returned by MovieRepository.TextSearch when
+	// the store has no full-text index; callers fall back to the search
+	// package.
+	ErrTextSearchUnsupported = errors.New("text search is not supported by this store")
+)
+
+// SortField is a movie ordering. Every ordering is tie-broken by _id, whose
+// ObjectID timestamp is the insertion time.
+type SortField string
+
+const (
+	SortByTitle   SortField = "title"
+	SortByRanking SortField = "ranking"
+	SortByCreated SortField = "created"
+)
+
+// MovieSort orders a movie listing.
+type MovieSort struct {
+	Field      SortField
+	Descending bool
+}
+
+// MovieFilter selects active movies. Zero fields do not filter.
+type MovieFilter struct {
+	// GenreIDs matches movies with at least one of the genres.
+	GenreIDs   []int
+	MinRanking *int
+	MaxRanking *int
+	// TitlePrefix is matched case-sensitively against the start of the title.
+	TitlePrefix    string
+	ExcludeImdbIDs []string
+}
+
+// Cursor is a keyset position: the sort value and _id of the last item of
+// the previous page. Value is a string for SortByTitle, an int for
+// SortByRanking and unused for SortByCreated.
+type Cursor struct {
+	Value interface{}
+	ID    primitive.ObjectID
+}
+
+// MovieQuery is one page of a filtered, sorted movie listing.
+type MovieQuery struct {
+	Filter MovieFilter
+	Sort   MovieSort
+	After  *Cursor
+	Limit  int
+}
+
+// Page is one page of a listing. Total counts every match regardless of the
+// page and More reports whether items follow this page.
+type Page[T any] struct {
+	Items []T
+	Total int64
+	More  bool
+}
+
+// EditableMovieFields returns the fields an admin may write. The audience
+// aggregates and soft-delete marker are managed by the server.
+func EditableMovieFields(movie models.Movie) bson.M {
+	return bson.M{
+		"imdb_id":      movie.ImdbID,
+		"title":        movie.Title,
+		"poster_path":  movie.PosterPath,
+		"youtube_id":   movie.YouTubeID,
+		"genre":        movie.Genre,
+		"admin_review": movie.AdminReview,
+		"ranking":      movie.Ranking,
+	}
+}
+
+// MovieRepository stores the movie catalogue. Soft deleted movies are only
+// visible to Insert, Upsert and Restore.
+type MovieRepository interface {
+	// Revision changes whenever a movie is written through the repository,
+	// so derived data such as a search index knows when to rebuild.
+	Revision() uint64
+
+	Page(ctx context.Context, query MovieQuery) (Page[models.Movie], error)
+	// Find returns every match in order, or the first limit when limit > 0.
+	Find(ctx context.Context, filter MovieFilter, sort MovieSort, limit int) ([]models.Movie, error)
+	FindByImdbIDs(ctx context.Context, imdbIDs []string) ([]models.Movie, error)
+	Get(ctx context.Context, imdbID string) (models.Movie, error)
+	Exists(ctx context.Context, imdbID string) (bool, error)
+	// TextSearch ranks movies with the store's own full-text index.
+	TextSearch(ctx context.Context, q string, offset, limit int) ([]models.MovieSearchResult, int64, error)
+
+	// Insert adds a movie and sets its ID. A deleted movie still owns its
+	// imdb_id, so inserting it again returns ErrDuplicate.
+	Insert(ctx context.Context, movie *models.Movie) error
+	// Replace overwrites the admin-editable fields of an active movie.
+	Replace(ctx context.Context, imdbID string, movie models.Movie) (models.Movie, error)
+	// Upsert writes the admin-editable fields on imdb_id, creating the movie
+	// when it does not exist. A soft deleted movie is left alone and
+	// ErrDeleted returned.
+	Upsert(ctx context.Context, movie models.Movie) (created bool, err error)
+	SetReview(ctx context.Context, imdbID, review string, ranking models.Ranking) (models.Movie, error)
+	SoftDelete(ctx context.Context, imdbID string, at time.Time) error
+	Restore(ctx context.Context, imdbID string) (models.Movie, error)
+
+	// CountWithGenre counts the movies, deleted ones included, listing the
+	// genre.
+	CountWithGenre(ctx context.Context, genreID int) (int64, error)
+	// ReplaceGenre swaps the genre with ID from for to in every movie,
+	// deleted ones included, keeping its place in the list. A movie already
+	// listing to just loses from, and a nil to removes from. It returns how
+	// many movies changed.
+	ReplaceGenre(ctx context.Context, from int, to *models.Genre) (int64, error)
+}
+
+// UserChanges lists the profile fields to update. Nil fields, and a nil
+// FavouriteGenres, are left alone.
+type UserChanges struct {
+	FirstName       *string
+	LastName        *string
+	PendingEmail    *string
+	FavouriteGenres []models.Genre
+	Role            *string
+	Status          *string
+}
+
+// UserFilter selects users for the admin listing. Zero fields do not filter,
+// except that deleted users are only listed when Status asks for them.
+type UserFilter struct {
+	// Query matches part of the email, first or last name, ignoring case.
+	Query  string
+	Role   string
+	Status string
+}
+
+// anonymous returns the fields that replace a deleted user's personal data.
+// The user_id is kept, so ratings and history stay attributable without
+// saying to whom.
+func anonymous(userID string) models.User {
+	return models.User{
+		UserID:          userID,
+		FirstName:       "Deleted",
+		LastName:        "User",
+		Email:           "deleted-" + userID + "@invalid",
+		FavouriteGenres: []models.Genre{},
+	}
+}
+
+// UserRepository stores user accounts.
+type UserRepository interface {
+	GetByID(ctx context.Context, userID string) (models.User, error)
+	GetByEmail(ctx context.Context, email string) (models.User, error)
+	// Insert adds a user, returning ErrDuplicate if the email is taken.
+	Insert(ctx context.Context, user *models.User) error
+	// Update applies changes to a user and returns the updated user.
+	Update(ctx context.Context, userID string, changes UserChanges, at time.Time) (models.User, error)
+	// MarkEmailVerified records that the user controls email. If email is
+	// their pending address it becomes their address, or ErrDuplicate if
+	// someone else has taken it meanwhile. It returns ErrNotFound when email
+	// is neither.
+	MarkEmailVerified(ctx context.Context, userID, email string, at time.Time) error
+	// SetPassword replaces the password hash.
+	SetPassword(ctx context.Context, userID, passwordHash string, at time.Time) error
+	// Page lists users newest first.
+	Page(ctx context.Context, filter UserFilter, offset, limit int) (Page[models.User], error)
+	// CountActiveAdmins counts the admins who are neither disabled nor
+	// deleted.
+	CountActiveAdmins(ctx context.Context) (int64, error)
+	// Delete soft deletes a user that is not deleted yet, optionally
+	// replacing their personal data and password with anonymous values,
+	// and returns the deleted user.
+	Delete(ctx context.Context, userID string, anonymise bool, at time.Time) (models.User, error)
+	// Lock refuses logins to the user until the given time.
+	Lock(ctx context.Context, userID string, until, at time.Time) error
+	// Unlock clears a lockout and returns the updated user.
+	Unlock(ctx context.Context, userID string, at time.Time) (models.User, error)
+	// ReplaceGenre swaps the favourite genre with ID from for to, like
+	// MovieRepository.ReplaceGenre, and returns how many users changed.
+	ReplaceGenre(ctx context.Context, from int, to *models.Genre) (int64, error)
+}
+
+// LoginAttemptRepository counts failed logins per email address and per
+// client IP.
+type LoginAttemptRepository interface {
+	// Get returns the counter for key, or ErrNotFound when there have been no
+	// failures since it was last reset or expired.
+	Get(ctx context.Context, key string) (models.LoginAttempt, error)
+	// RecordFailure adds a failure at the given time and returns the counter.
+	// A counter whose last failure is before forgetBefore starts again from
+	// one. The counter is kept until expiresAt.
+	RecordFailure(ctx context.Context, key string, at, forgetBefore, expiresAt time.Time) (models.LoginAttempt, error)
+	Reset(ctx context.Context, key string) error
+}
+
+// UserTokenRepository stores the single-use tokens mailed to users.
+type UserTokenRepository interface {
+	Create(ctx context.Context, token *models.UserToken) error
+	// Consume marks the unused, unexpired token with the given purpose and
+	// hash as used and returns it. Unknown, used and expired tokens all
+	// return ErrNotFound.
+	Consume(ctx context.Context, purpose, hash string, now time.Time) (models.UserToken, error)
+	// InvalidateUser uses up a user's outstanding tokens for purpose, so
+	// only the newest one mailed works.
+	InvalidateUser(ctx context.Context, userID, purpose string, now time.Time) error
+}
+
+// SessionRepository stores the refresh-token sessions of signed-in devices.
+type SessionRepository interface {
+	Create(ctx context.Context, session *models.Session) error
+	// GetByTokenHash finds a session, active or not, by its token hash.
+	GetByTokenHash(ctx context.Context, hash string) (models.Session, error)
+	// Rotate revokes the session with ID current and inserts next in its
+	// place. It returns ErrNotFound if current was already revoked, such as
+	// by a concurrent refresh with the same token.
+	Rotate(ctx context.Context, current primitive.ObjectID, next *models.Session, at time.Time) error
+	// RevokeFamily revokes the active session of one of a user's families,
+	// returning ErrNotFound when there is none.
+	RevokeFamily(ctx context.Context, userID, familyID, reason string, at time.Time) error
+	// RevokeUser revokes every active session of a user and returns their
+	// family IDs.
+	RevokeUser(ctx context.Context, userID, reason string, at time.Time) ([]string, error)
+	// ListActive lists a user's unrevoked, unexpired sessions, most recently
+	// used first.
+	ListActive(ctx context.Context, userID string, now time.Time) ([]models.Session, error)
+}
+
+// RevocationRepository stores the access-token revocation list.
+type RevocationRepository interface {
+	Revoke(ctx context.Context, revocation models.Revocation) error
+	// IsRevoked reports whether an unexpired revocation matches a token with
+	// the given jti, session and user that was issued at issuedAt.
+	IsRevoked(ctx context.Context, tokenID, sessionID, userID string, issuedAt time.Time) (bool, error)
+}
+
+// RateLimitRepository keeps the token buckets of the rate limiter.
+type RateLimitRepository interface {
+	// Take refills the bucket for key at rate tokens per second, up to
+	// capacity, then takes a token if one is whole. A new bucket starts
+	// full. The returned bucket says whether a token was taken and how many
+	// are left.
+	Take(ctx context.Context, key string, capacity int, rate float64, now time.Time) (models.RateLimitBucket, error)
+}
+
+// AuditFilter selects audit entries. Zero fields do not filter.
+type AuditFilter struct {
+	ActorID  string
+	TargetID string
+}
+
+// AuditRepository stores the audit trail of admin actions. Entries are
+// never changed or removed.
+type AuditRepository interface {
+	Record(ctx context.Context, entry models.AuditEntry) error
+	// Page lists entries newest first.
+	Page(ctx context.Context, filter AuditFilter, offset, limit int) (Page[models.AuditEntry], error)
+}
+
+// GenreRepository stores the canonical genre list. Movies and users embed
+// copies of genres; MovieRepository.ReplaceGenre and
+// UserRepository.ReplaceGenre keep those in step.
+type GenreRepository interface {
+	// List returns every genre ordered by ID.
+	List(ctx context.Context) ([]models.Genre, error)
+	Get(ctx context.Context, genreID int) (models.Genre, error)
+	// Insert adds a genre, returning ErrDuplicate if the ID is taken.
+	Insert(ctx context.Context, genre models.Genre) error
+	Rename(ctx context.Context, genreID int, name string) (models.Genre, error)
+	Delete(ctx context.Context, genreID int) error
+}
+
+// RankingRepository stores the rankings reviews are classified into.
+type RankingRepository interface {
+	// List returns every ranking ordered by value, best first.
+	List(ctx context.Context) ([]models.Ranking, error)
+}
+
+// RatingFilter selects ratings by user, by movie or both.
+type RatingFilter struct {
+	UserID string
+	ImdbID string
+}
+
+// RatingRepository stores the star ratings users give movies, at most one
+// per user and movie.
+type RatingRepository interface {
+	// Upsert creates or replaces the stars and review of rating.UserID for
+	// rating.ImdbID.
+	Upsert(ctx context.Context, rating models.Rating) (models.Rating, bool, error)
+	Delete(ctx context.Context, userID, imdbID string) error
+	// Page lists ratings newest first, resuming after the rating with the
+	// given ID when it is not zero.
+	Page(ctx context.Context, filter RatingFilter, after primitive.ObjectID, limit int) (Page[models.Rating], error)
+	// SyncAudienceScore sets the audience_score and rating_count of a movie,
+	// deleted or not, to the mean stars rounded to two decimals and the
+	// number of its ratings, computing and writing both in one step.
+	SyncAudienceScore(ctx context.Context, imdbID string) error
+	RatedImdbIDs(ctx context.Context, userID string) ([]string, error)
+}
+
+// WatchlistRepository stores the movies each user wants to watch.
+type WatchlistRepository interface {
+	// Add is idempotent; created is false when the movie was already listed.
+	Add(ctx context.Context, userID, imdbID string, at time.Time) (models.WatchlistItem, bool, error)
+	Remove(ctx context.Context, userID, imdbID string) error
+	// Page lists a user's watchlist most recently added first.
+	Page(ctx context.Context, userID string, offset, limit int) (Page[models.WatchlistItem], error)
+}
+
+// WatchHistoryRepository stores how far each user got through each movie.
+type WatchHistoryRepository interface {
+	// Record saves a playback event. Once finished, an entry stays finished.
+	Record(ctx context.Context, userID string, event models.PlaybackEvent, at time.Time) (models.WatchHistory, bool, error)
+	// Page lists a user's history most recently watched first, optionally
+	// only finished or unfinished entries.
+	Page(ctx context.Context, userID string, finished *bool, offset, limit int) (Page[models.WatchHistory], error)
+	FinishedImdbIDs(ctx context.Context, userID string) ([]string, error)
+}
+
+// Store bundles the repositories the HTTP API is built on.
+type Store struct {
+	Movies      MovieRepository
+	Users       UserRepository
+	Sessions    SessionRepository
+	Revocations RevocationRepository
+	UserTokens  UserTokenRepository
+	Logins      LoginAttemptRepository
+	RateLimits  RateLimitRepository
+	Audit       AuditRepository
+	Genres      GenreRepository
+	Rankings    RankingRepository
+	Ratings     RatingRepository
+	Watchlist   WatchlistRepository
+	History     WatchHistoryRepository
+}
@@ -0,0 +1,131 @@
+package routes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/config"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/fixtures"
+	model "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+)
+
+// genreCatalogue has a movie in each genre and a user who likes Comedy and
+// Western.
+func genreCatalogue(t *testing.T) fixtures.Set {
+	fan := testUser(t, "fan", "USER")
+	fan.FavouriteGenres = []model.Genre{testGenres[0], testGenres[2]}
+	return fixtures.Set{
+		Genres: testGenres,
+		Movies: []model.Movie{
+			testMovie("tt0000001", "Laughs", testGenres[0]),
+			testMovie("tt0000002", "Tears", testGenres[1]),
+			testMovie("tt0000003", "Horses", testGenres[2], testGenres[1]),
+		},
+		Users: []model.User{testUser(t, "admin", "ADMIN"), fan},
+	}
+}
+
+// movieGenres returns the genres embedded in a movie as "id:name" pairs.
+func movieGenres(t *testing.T, s *testServer, imdbID string) string {
+	t.Helper()
+	movie, err := s.store.Movies.Get(context.Background(), imdbID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return genreList(movie.Genre)
+}
+
+// favouriteGenres returns the favourite genres of a user as "id:name" pairs.
+func favouriteGenres(t *testing.T, s *testServer, userID string) string {
+	t.Helper()
+	user, err := s.store.Users.GetByID(context.Background(), userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return genreList(user.FavouriteGenres)
+}
+
+func genreList(genres []model.Genre) string {
+	pairs := []string{}
+	for _, genre := range genres {
+		pairs = append(pairs, fmt.Sprintf("%d:%s", genre.GenreID, genre.GenreName))
+	}
+	return fmt.Sprint(pairs)
+}
+
+func TestRenameGenreUpdatesCopies(t *testing.T) {
+	s := newTestServer(t, genreCatalogue(t), func(cfg *config.Config) { cfg.RateLimit.Enabled = false })
+	admin := s.login(t, "admin")
+
+	recorder := s.do(http.MethodPut, "/admin/genres/1", `{"genre_name":"Comedies"}`, bearer(admin.Token)...)
+	expectStatus(t, recorder, http.StatusOK)
+	var response struct {
+		MoviesUpdated int64 `json:"movies_updated"`
+		UsersUpdated  int64 `json:"users_updated"`
+	}
+	decode(t, recorder, &response)
+	if response.MoviesUpdated != 1 || response.UsersUpdated != 1 {
+		t.Errorf("updated %d movies and %d users, want 1 and 1", response.MoviesUpdated, response.UsersUpdated)
+	}
+
+	if got := movieGenres(t, s, "tt0000001"); got != "[1:Comedies]" {
+		t.Errorf("movie genres %s after the rename", got)
+	}
+	if got := favouriteGenres(t, s, "fan"); got != "[1:Comedies 3:Western]" {
+		t.Errorf("favourite genres %s after the rename", got)
+	}
+
+	// The old name is no longer accepted on writes, the new one is
+	old := `{"imdb_id":"tt0000009","title":"More Laughs","poster_path":"https://example.com/9.jpg","youtube_id":"yt9",
+		"genre":[{"genre_id":1,"genre_name":"%s"}],"ranking":{"ranking_value":999,"ranking_name":"Not_Ranked"}}`
+	expectStatus(t, s.do(http.MethodPost, "/addmovie", fmt.Sprintf(old, "Comedy"), bearer(admin.Token)...), http.StatusBadRequest)
+	expectStatus(t, s.do(http.MethodPost, "/addmovie", fmt.Sprintf(old, "Comedies"), bearer(admin.Token)...), http.StatusCreated)
+
+	expectStatus(t, s.do(http.MethodPut, "/admin/genres/2", `{"genre_name":"comedies"}`, bearer(admin.Token)...), http.StatusConflict)
+}
+
+func TestDeleteGenreInUse(t *testing.T) {
+	s := newTestServer(t, genreCatalogue(t), func(cfg *config.Config) { cfg.RateLimit.Enabled = false })
+	admin := s.login(t, "admin")
+
+	expectStatus(t, s.do(http.MethodDelete, "/admin/genres/3", "", bearer(admin.Token)...), http.StatusConflict)
+	expectStatus(t, s.do(http.MethodDelete, "/admin/genres/3?reassign_to=3", "", bearer(admin.Token)...), http.StatusBadRequest)
+	expectStatus(t, s.do(http.MethodDelete, "/admin/genres/3?reassign_to=42", "", bearer(admin.Token)...), http.StatusBadRequest)
+	if got := movieGenres(t, s, "tt0000003"); got != "[3:Western 2:Drama]" {
+		t.Fatalf("refused deletions changed the movie genres to %s", got)
+	}
+
+	expectStatus(t, s.do(http.MethodDelete, "/admin/genres/3?reassign_to=2", "", bearer(admin.Token)...), http.StatusOK)
+
+	// Drama was already listed, so it is not listed twice
+	if got := movieGenres(t, s, "tt0000003"); got != "[2:Drama]" {
+		t.Errorf("movie genres %s after reassigning Western to Drama", got)
+	}
+	if got := favouriteGenres(t, s, "fan"); got != "[1:Comedy 2:Drama]" {
+		t.Errorf("favourite genres %s after reassigning Western to Drama", got)
+	}
+	expectStatus(t, s.do(http.MethodDelete, "/admin/genres/3", "", bearer(admin.Token)...), http.StatusNotFound)
+}
+
+func TestDeleteUnusedGenreDropsFavourites(t *testing.T) {
+	seed := genreCatalogue(t)
+	seed.Movies = seed.Movies[:2]
+	seed.Movies = append(seed.Movies, testMovie("tt0000003", "Horses", testGenres[1]))
+	s := newTestServer(t, seed, func(cfg *config.Config) { cfg.RateLimit.Enabled = false })
+	admin := s.login(t, "admin")
+
+	expectStatus(t, s.do(http.MethodDelete, "/admin/genres/3", "", bearer(admin.Token)...), http.StatusOK)
+	if got := favouriteGenres(t, s, "fan"); got != "[1:Comedy]" {
+		t.Errorf("favourite genres %s after deleting Western", got)
+	}
+
+	var listing struct {
+		Genres []model.Genre `json:"genres"`
+	}
+	decode(t, s.do(http.MethodGet, "/genres", ""), &listing)
+	if got := genreList(listing.Genres); got != "[1:Comedy 2:Drama]" {
+		t.Errorf("genre list %s after deleting Western", got)
+	}
+}
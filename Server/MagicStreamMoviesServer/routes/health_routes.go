@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	controller "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/controllers"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/health"
+)
+
+// SetupHealthRoutes registers the liveness and readiness probes.
+func SetupHealthRoutes(router *gin.Engine, probe *health.Probe) {
+	router.GET("/healthz", controller.Healthz())
+	router.GET("/readyz", controller.Readyz(probe))
+}
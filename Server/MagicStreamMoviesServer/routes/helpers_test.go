@@ -0,0 +1,161 @@
+package routes
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/config"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/fixtures"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/jwks"
+	model "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/repository"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testRemoteAddr is the peer address of every test request.
+const testRemoteAddr = "203.0.113.7:40000"
+
+// testPassword is the password of every user made by testUser.
+const testPassword = "secret-pw1"
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	gin.DefaultWriter = io.Discard
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// testServer is the whole HTTP API on a memory store.
+type testServer struct {
+	router *gin.Engine
+	store  *repository.Store
+	tokens *utils.Tokens
+}
+
+// newTestServer builds the API the way main does, on a memory store holding
+// seed. configure may adjust the defaults first.
+func newTestServer(t *testing.T, seed fixtures.Set, configure func(cfg *config.Config)) *testServer {
+	t.Helper()
+
+	cfg := config.Default()
+	cfg.Storage = "memory"
+	cfg.Auth.KeyDir = t.TempDir()
+	if configure != nil {
+		configure(&cfg)
+	}
+
+	keys, err := jwks.Load(jwks.Options{Dir: cfg.Auth.KeyDir, Algorithm: cfg.Auth.SigningAlgorithm, Generate: true})
+	if err != nil {
+		t.Fatalf("load keys: %v", err)
+	}
+	tokens := utils.NewTokens(keys, cfg.Auth)
+	store := repository.NewMemoryStore(seed)
+
+	router, err := NewRouter(&cfg)
+	if err != nil {
+		t.Fatalf("new router: %v", err)
+	}
+	SetupProtectedRoutes(router, store, &cfg, tokens)
+	SetupUnProtectedRoutes(router, store, &cfg, tokens)
+	return &testServer{router: router, store: store, tokens: tokens}
+}
+
+// do sends a request with an optional JSON body and header pairs, such as
+// "Authorization", "Bearer ...".
+func (s *testServer) do(method, path, body string, headers ...string) *httptest.ResponseRecorder {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	req.RemoteAddr = testRemoteAddr
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+
+	recorder := httptest.NewRecorder()
+	s.router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// decode unmarshals a JSON response body into v.
+func decode(t *testing.T, recorder *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(recorder.Body.Bytes(), v); err != nil {
+		t.Fatalf("decode %q: %v", recorder.Body.String(), err)
+	}
+}
+
+// expectStatus fails the test unless the response has the wanted status.
+func expectStatus(t *testing.T, recorder *httptest.ResponseRecorder, want int) {
+	t.Helper()
+	if recorder.Code != want {
+		t.Fatalf("status %d, want %d: %s", recorder.Code, want, recorder.Body.String())
+	}
+}
+
+// bearer returns the header pair authorising a request with token.
+func bearer(token string) []string {
+	return []string{"Authorization", "Bearer " + token}
+}
+
+// testUser returns a verified, active user with testPassword.
+func testUser(t *testing.T, userID, role string) model.User {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(testPassword), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return model.User{
+		UserID:          userID,
+		FirstName:       "Test",
+		LastName:        "User",
+		Email:           userID + "@example.com",
+		Password:        string(hash),
+		Role:            role,
+		FavouriteGenres: []model.Genre{},
+		EmailVerified:   true,
+		Status:          model.UserActive,
+	}
+}
+
+// login signs a testUser in and returns the login response.
+func (s *testServer) login(t *testing.T, userID string) model.UserResponse {
+	t.Helper()
+	recorder := s.do(http.MethodPost, "/login", `{"email":"`+userID+`@example.com","password":"`+testPassword+`"}`)
+	expectStatus(t, recorder, http.StatusOK)
+
+	var response model.UserResponse
+	decode(t, recorder, &response)
+	return response
+}
+
+// testGenres is the genre list of the test catalogues.
+var testGenres = []model.Genre{
+	{GenreID: 1, GenreName: "Comedy"},
+	{GenreID: 2, GenreName: "Drama"},
+	{GenreID: 3, GenreName: "Western"},
+}
+
+// testMovie returns a valid movie in the given genres.
+func testMovie(imdbID, title string, genres ...model.Genre) model.Movie {
+	return model.Movie{
+		ImdbID:     imdbID,
+		Title:      title,
+		PosterPath: "https://example.com/" + imdbID + ".jpg",
+		YouTubeID:  "yt" + imdbID,
+		Genre:      genres,
+		Ranking:    model.Ranking{RankingValue: 999, RankingName: "Not_Ranked"},
+	}
+}
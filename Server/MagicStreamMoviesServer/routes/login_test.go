@@ -0,0 +1,74 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/config"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/fixtures"
+	model "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+)
+
+// failLogins sends n failed logins, each for a new address and with a new
+// X-Forwarded-For, so only the per-IP counter can throttle them.
+func failLogins(s *testServer, n int) []int {
+	codes := make([]int, n)
+	for i := range codes {
+		body := fmt.Sprintf(`{"email":"nobody%d@example.com","password":"wrongpw1"}`, i)
+		codes[i] = s.do(http.MethodPost, "/login", body, "X-Forwarded-For", fmt.Sprintf("9.9.9.%d", i+1)).Code
+	}
+	return codes
+}
+
+func TestLoginIPCounterIgnoresForgedForwardedFor(t *testing.T) {
+	s := newTestServer(t, fixtures.Set{}, func(cfg *config.Config) {
+		cfg.RateLimit.Enabled = false
+		cfg.Login.IPFreeAttempts = 3
+		// Long enough that a slow run cannot wait the backoff out
+		cfg.Login.BackoffBase = time.Hour
+		cfg.Login.BackoffMax = time.Hour
+	})
+
+	codes := failLogins(s, 5)
+	want := []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests, http.StatusTooManyRequests}
+	if fmt.Sprint(codes) != fmt.Sprint(want) {
+		t.Fatalf("statuses %v, want %v: a forged X-Forwarded-For must not start a new IP counter", codes, want)
+	}
+}
+
+func TestLoginIPCounterTrustsConfiguredProxy(t *testing.T) {
+	s := newTestServer(t, fixtures.Set{}, func(cfg *config.Config) {
+		cfg.RateLimit.Enabled = false
+		cfg.Login.IPFreeAttempts = 3
+		cfg.Server.TrustedProxies = []string{"203.0.113.0/24"}
+	})
+
+	for i, code := range failLogins(s, 5) {
+		if code != http.StatusUnauthorized {
+			t.Fatalf("login %d: status %d, want 401 as each client behind the proxy has its own counter", i, code)
+		}
+	}
+}
+
+func TestLockedAccountAnswersTheSameForAnyPassword(t *testing.T) {
+	user := testUser(t, "locked", "USER")
+	until := time.Now().Add(time.Hour)
+	user.LockedUntil = &until
+	s := newTestServer(t, fixtures.Set{Users: []model.User{user}}, func(cfg *config.Config) { cfg.RateLimit.Enabled = false })
+
+	right := s.do(http.MethodPost, "/login", fmt.Sprintf(`{"email":%q,"password":%q}`, user.Email, testPassword))
+	wrong := s.do(http.MethodPost, "/login", fmt.Sprintf(`{"email":%q,"password":"wrongpw1"}`, user.Email))
+	expectStatus(t, right, http.StatusTooManyRequests)
+	expectStatus(t, wrong, http.StatusTooManyRequests)
+
+	var rightBody, wrongBody struct {
+		Error string `json:"error"`
+	}
+	decode(t, right, &rightBody)
+	decode(t, wrong, &wrongBody)
+	if rightBody.Error != wrongBody.Error {
+		t.Errorf("right password answered %q, wrong password %q", rightBody.Error, wrongBody.Error)
+	}
+}
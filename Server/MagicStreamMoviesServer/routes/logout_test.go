@@ -0,0 +1,49 @@
+package routes
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/fixtures"
+	model "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+)
+
+func TestLogoutEndsOnlyTheCallersSession(t *testing.T) {
+	s := newTestServer(t, fixtures.Set{Users: []model.User{testUser(t, "alice", "USER")}}, nil)
+	session := s.login(t, "alice")
+	other := s.login(t, "alice")
+
+	expectStatus(t, s.do(http.MethodPost, "/logout", "", bearer(session.Token)...), http.StatusNoContent)
+	expectStatus(t, s.do(http.MethodGet, "/me", "", bearer(session.Token)...), http.StatusUnauthorized)
+	expectStatus(t, s.refresh(session.RefreshToken), http.StatusUnauthorized)
+	expectStatus(t, s.do(http.MethodGet, "/me", "", bearer(other.Token)...), http.StatusOK)
+}
+
+func TestLogoutEverywhere(t *testing.T) {
+	s := newTestServer(t, fixtures.Set{Users: []model.User{testUser(t, "alice", "USER")}}, nil)
+	session := s.login(t, "alice")
+	other := s.login(t, "alice")
+
+	expectStatus(t, s.do(http.MethodPost, "/logout?everywhere=true", "", bearer(session.Token)...), http.StatusNoContent)
+	expectStatus(t, s.do(http.MethodGet, "/me", "", bearer(other.Token)...), http.StatusUnauthorized)
+	expectStatus(t, s.refresh(other.RefreshToken), http.StatusUnauthorized)
+}
+
+func TestLogoutWithRefreshTokenOnly(t *testing.T) {
+	s := newTestServer(t, fixtures.Set{Users: []model.User{testUser(t, "alice", "USER")}}, nil)
+	session := s.login(t, "alice")
+
+	expectStatus(t, s.do(http.MethodPost, "/logout", `{"refresh_token":"`+session.RefreshToken+`"}`), http.StatusNoContent)
+	expectStatus(t, s.refresh(session.RefreshToken), http.StatusUnauthorized)
+	expectStatus(t, s.do(http.MethodGet, "/me", "", bearer(session.Token)...), http.StatusUnauthorized)
+}
+
+func TestLogoutCannotNameAnotherUser(t *testing.T) {
+	seed := fixtures.Set{Users: []model.User{testUser(t, "alice", "USER"), testUser(t, "bob", "USER")}}
+	s := newTestServer(t, seed, nil)
+	bob := s.login(t, "bob")
+
+	// A user_id in the body is ignored; without credentials nothing happens
+	expectStatus(t, s.do(http.MethodPost, "/logout", `{"user_id":"bob","everywhere":true}`), http.StatusNoContent)
+	expectStatus(t, s.do(http.MethodGet, "/me", "", bearer(bob.Token)...), http.StatusOK)
+}
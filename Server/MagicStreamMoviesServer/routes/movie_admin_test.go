@@ -0,0 +1,55 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/controllers"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/fixtures"
+	model "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+)
+
+func TestImportReportsDeletedMovies(t *testing.T) {
+	s := newTestServer(t, fixtures.Set{
+		Genres: testGenres,
+		Movies: []model.Movie{testMovie("tt0000001", "Gone Movie", testGenres[0])},
+		Users:  []model.User{testUser(t, "admin", "ADMIN")},
+	}, nil)
+	if err := s.store.Movies.SoftDelete(context.Background(), "tt0000001", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	admin := s.login(t, "admin")
+
+	body := `[
+		{"imdb_id":"tt0000001","title":"Gone Movie Again","poster_path":"https://example.com/1.jpg","youtube_id":"yt1",
+		 "genre":[{"genre_id":1,"genre_name":"Comedy"}],"ranking":{"ranking_value":999,"ranking_name":"Not_Ranked"}},
+		{"imdb_id":"tt0000002","title":"New Movie","poster_path":"https://example.com/2.jpg","youtube_id":"yt2",
+		 "genre":[{"genre_id":2,"genre_name":"Drama"}],"ranking":{"ranking_value":999,"ranking_name":"Not_Ranked"}}
+	]`
+	recorder := s.do(http.MethodPost, "/movies/import", body, bearer(admin.Token)...)
+	expectStatus(t, recorder, http.StatusOK)
+
+	var response struct {
+		Created, Updated, Deleted, Failed int
+		Results                           []controllers.ImportResult
+	}
+	decode(t, recorder, &response)
+	if response.Created != 1 || response.Updated != 0 || response.Deleted != 1 || response.Failed != 0 {
+		t.Fatalf("counts %+v, want 1 created and 1 deleted", response)
+	}
+	if status := response.Results[0].Status; status != "deleted" {
+		t.Errorf("deleted movie reported as %q", status)
+	}
+
+	// The hidden movie is untouched and still hidden
+	expectStatus(t, s.do(http.MethodGet, "/movie/tt0000001", "", bearer(admin.Token)...), http.StatusNotFound)
+	restored := s.do(http.MethodPost, "/movies/tt0000001/restore", "", bearer(admin.Token)...)
+	expectStatus(t, restored, http.StatusOK)
+	var movie model.Movie
+	decode(t, restored, &movie)
+	if movie.Title != "Gone Movie" {
+		t.Errorf("import changed the deleted movie's title to %q", movie.Title)
+	}
+}
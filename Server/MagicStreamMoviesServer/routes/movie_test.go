@@ -0,0 +1,103 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/config"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/fixtures"
+	model "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+)
+
+// catalogue is five movies whose rankings tie in pairs, so a cursor has to
+// fall back to _id to page through them.
+func catalogue() fixtures.Set {
+	movies := []model.Movie{
+		testMovie("tt0000001", "Echo", testGenres[0]),
+		testMovie("tt0000002", "Alpha", testGenres[1]),
+		testMovie("tt0000003", "Delta", testGenres[0]),
+		testMovie("tt0000004", "Bravo", testGenres[2]),
+		testMovie("tt0000005", "Charlie", testGenres[1]),
+	}
+	for i := range movies {
+		movies[i].Ranking = model.Ranking{RankingValue: i/2 + 1, RankingName: fmt.Sprint("Rank ", i/2+1)}
+	}
+	return fixtures.Set{Genres: testGenres, Movies: movies}
+}
+
+// moviePage fetches one page of GET /movies.
+func moviePage(t *testing.T, s *testServer, query string) model.Page[model.Movie] {
+	t.Helper()
+	recorder := s.do(http.MethodGet, "/movies?"+query, "")
+	expectStatus(t, recorder, http.StatusOK)
+
+	var page model.Page[model.Movie]
+	decode(t, recorder, &page)
+	return page
+}
+
+// titles lists the titles on a page.
+func titles(page model.Page[model.Movie]) string {
+	names := []string{}
+	for _, movie := range page.Items {
+		names = append(names, movie.Title)
+	}
+	return fmt.Sprint(names)
+}
+
+func TestMovieCursorPagination(t *testing.T) {
+	s := newTestServer(t, catalogue(), func(cfg *config.Config) { cfg.RateLimit.Enabled = false })
+
+	first := moviePage(t, s, "limit=2&sort=title")
+	if titles(first) != "[Alpha Bravo]" || first.Total != 5 || first.NextCursor == "" {
+		t.Fatalf("first page %s of %d, cursor %q", titles(first), first.Total, first.NextCursor)
+	}
+	second := moviePage(t, s, "limit=2&sort=title&cursor="+url.QueryEscape(first.NextCursor))
+	if titles(second) != "[Charlie Delta]" || second.Total != 5 || second.NextCursor == "" {
+		t.Fatalf("second page %s of %d, cursor %q", titles(second), second.Total, second.NextCursor)
+	}
+	last := moviePage(t, s, "limit=2&sort=title&cursor="+url.QueryEscape(second.NextCursor))
+	if titles(last) != "[Echo]" || last.NextCursor != "" {
+		t.Fatalf("last page %s, cursor %q", titles(last), last.NextCursor)
+	}
+}
+
+func TestMovieCursorBreaksTiesByID(t *testing.T) {
+	s := newTestServer(t, catalogue(), func(cfg *config.Config) { cfg.RateLimit.Enabled = false })
+
+	// Echo and Alpha share ranking 1, Delta and Bravo ranking 2
+	first := moviePage(t, s, "limit=1&sort=ranking")
+	second := moviePage(t, s, "limit=1&sort=ranking&cursor="+url.QueryEscape(first.NextCursor))
+	if titles(first) != "[Echo]" || titles(second) != "[Alpha]" {
+		t.Errorf("ranking pages %s then %s, want [Echo] then [Alpha]", titles(first), titles(second))
+	}
+
+	first = moviePage(t, s, "limit=1&sort=-ranking")
+	second = moviePage(t, s, "limit=1&sort=-ranking&cursor="+url.QueryEscape(first.NextCursor))
+	if titles(first) != "[Charlie]" || titles(second) != "[Bravo]" {
+		t.Errorf("-ranking pages %s then %s, want [Charlie] then [Bravo]", titles(first), titles(second))
+	}
+}
+
+func TestMovieFilters(t *testing.T) {
+	s := newTestServer(t, catalogue(), nil)
+
+	if got := titles(moviePage(t, s, "genre_id=1&sort=title")); got != "[Delta Echo]" {
+		t.Errorf("genre 1 lists %s", got)
+	}
+	if got := titles(moviePage(t, s, "min_ranking=2&max_ranking=2&sort=title")); got != "[Bravo Delta]" {
+		t.Errorf("ranking 2 lists %s", got)
+	}
+}
+
+func TestMovieCursorIsBoundToItsSort(t *testing.T) {
+	s := newTestServer(t, catalogue(), nil)
+
+	first := moviePage(t, s, "limit=2&sort=title")
+	recorder := s.do(http.MethodGet, "/movies?limit=2&sort=ranking&cursor="+url.QueryEscape(first.NextCursor), "")
+	expectStatus(t, recorder, http.StatusBadRequest)
+	expectStatus(t, s.do(http.MethodGet, "/movies?cursor=not-a-cursor", ""), http.StatusBadRequest)
+	expectStatus(t, s.do(http.MethodGet, "/movies?limit=0", ""), http.StatusBadRequest)
+}
@@ -2,19 +2,59 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/ai"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/config"
 	controllers "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/controllers"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/loginguard"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/mailer"
 	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/middleware"
-	"go.mongodb.org/mongo-driver/mongo"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/recommendation"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/repository"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/utils"
 )
 
-func SetupProtectedRoutes(router *gin.Engine, client *mongo.Client) {
-	// Apply auth middleware to protected routes
+func SetupProtectedRoutes(router *gin.Engine, store *repository.Store, cfg *config.Config, tokens *utils.Tokens) {
+	reviewClassifier := ai.NewReviewClassifier(cfg.Classifier)
+	// config.Validate has already rejected unknown strategies
+	coldStart, _ := recommendation.ParseColdStartStrategy(cfg.Recommendation.ColdStart)
+	guard := loginguard.New(store.Logins, store.Users, cfg.Login)
+	mail := mailer.New(cfg.Mail)
+
+	// Apply auth middleware to protected routes; each route then declares the
+	// permission it needs from the matrix in middleware.rolePermissions
 	protected := router.Group("/")
-	protected.Use(middleware.AuthMiddleWare())
+	protected.Use(middleware.AuthMiddleWare(tokens, store.Revocations))
+	protected.Use(rateLimit(store, cfg, "api", cfg.RateLimit.API))
 	{
-		protected.GET("/movie/:imdb_id", controllers.GetMovieByID())
-		protected.POST("/addmovie", controllers.AddMovie(client))
-		protected.GET("/recommendedmovies", controllers.GetRecommendedMovies(client))
-		protected.PATCH("/updatereview/:imdb_id", controllers.AdminReviewUpdate(client))
+		protected.GET("/movie/:imdb_id", middleware.RequirePermission(middleware.PermMovieRead), controllers.GetMovieByID(store.Movies))
+		protected.POST("/addmovie", middleware.RequirePermission(middleware.PermMovieCreate), controllers.AddMovie(store.Movies, store.Genres))
+		protected.PUT("/movies/:imdb_id", middleware.RequirePermission(middleware.PermMovieUpdate), controllers.UpdateMovie(store.Movies, store.Genres))
+		protected.PATCH("/movies/:imdb_id", middleware.RequirePermission(middleware.PermMovieUpdate), controllers.PatchMovie(store.Movies, store.Genres))
+		protected.DELETE("/movies/:imdb_id", middleware.RequirePermission(middleware.PermMovieDelete), controllers.DeleteMovie(store.Movies))
+		protected.POST("/movies/:imdb_id/restore", middleware.RequirePermission(middleware.PermMovieDelete), controllers.RestoreMovie(store.Movies))
+		protected.POST("/movies/import", middleware.RequirePermission(middleware.PermMovieCreate, middleware.PermMovieUpdate), controllers.ImportMovies(store.Movies, store.Genres))
+		protected.GET("/recommendedmovies", middleware.RequirePermission(middleware.PermMovieRead), controllers.GetRecommendedMovies(store.Users, store.Movies, store.Ratings, store.History, coldStart))
+		protected.PATCH("/updatereview/:imdb_id", middleware.RequirePermission(middleware.PermReviewUpdate), controllers.AdminReviewUpdate(store.Movies, store.Rankings, reviewClassifier))
+		protected.PUT("/movies/:imdb_id/rating", middleware.RequirePermission(middleware.PermRatingWrite), controllers.RateMovie(store.Movies, store.Ratings))
+		protected.DELETE("/movies/:imdb_id/rating", middleware.RequirePermission(middleware.PermRatingWrite), controllers.DeleteRating(store.Ratings))
+		protected.GET("/me", middleware.RequirePermission(middleware.PermProfileRead), controllers.GetProfile(store.Users))
+		protected.PATCH("/me", middleware.RequirePermission(middleware.PermProfileWrite), controllers.UpdateProfile(store.Users, store.UserTokens, store.Sessions, store.Revocations, tokens, mail, cfg.Account))
+		protected.PUT("/me/favourite-genres", middleware.RequirePermission(middleware.PermProfileWrite), controllers.SetFavouriteGenres(store.Users, store.Genres))
+		protected.GET("/me/sessions", middleware.RequirePermission(middleware.PermProfileRead), controllers.GetSessions(store.Sessions))
+		protected.DELETE("/me/sessions/:session_id", middleware.RequirePermission(middleware.PermProfileWrite), controllers.DeleteSession(store.Sessions, store.Revocations, tokens))
+		protected.GET("/admin/users", middleware.RequirePermission(middleware.PermUserManage), controllers.ListUsers(store.Users))
+		protected.PATCH("/admin/users/:user_id", middleware.RequirePermission(middleware.PermUserManage), controllers.UpdateUser(store.Users, store.Sessions, store.Revocations, tokens, store.Audit))
+		protected.DELETE("/admin/users/:user_id", middleware.RequirePermission(middleware.PermUserManage), controllers.DeleteUser(store.Users, store.Sessions, store.Revocations, tokens, store.Audit))
+		protected.POST("/admin/users/:user_id/unlock", middleware.RequirePermission(middleware.PermUserManage), controllers.UnlockUser(guard, store.Audit))
+		protected.GET("/admin/audit", middleware.RequirePermission(middleware.PermUserManage), controllers.GetAuditLog(store.Audit))
+		protected.POST("/admin/genres", middleware.RequirePermission(middleware.PermGenreManage), controllers.CreateGenre(store.Genres))
+		protected.PUT("/admin/genres/:genre_id", middleware.RequirePermission(middleware.PermGenreManage), controllers.RenameGenre(store.Genres, store.Movies, store.Users))
+		protected.DELETE("/admin/genres/:genre_id", middleware.RequirePermission(middleware.PermGenreManage), controllers.DeleteGenre(store.Genres, store.Movies, store.Users))
+		protected.GET("/me/ratings", middleware.RequirePermission(middleware.PermProfileRead), controllers.GetMyRatings(store.Ratings))
+		protected.GET("/me/watchlist", middleware.RequirePermission(middleware.PermProfileRead), controllers.GetWatchlist(store.Watchlist, store.Movies))
+		protected.POST("/me/watchlist", middleware.RequirePermission(middleware.PermProfileWrite), controllers.AddToWatchlist(store.Watchlist, store.Movies))
+		protected.DELETE("/me/watchlist/:imdb_id", middleware.RequirePermission(middleware.PermProfileWrite), controllers.RemoveFromWatchlist(store.Watchlist))
+		protected.GET("/me/history", middleware.RequirePermission(middleware.PermProfileRead), controllers.GetWatchHistory(store.History, store.Movies))
+		protected.POST("/me/history", middleware.RequirePermission(middleware.PermProfileWrite), controllers.RecordPlayback(store.History, store.Movies))
 	}
 }
\ No newline at end of file
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/config"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/middleware"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/repository"
+)
+
+// rateLimit returns the middleware enforcing the named policy, or one that
+// lets everything through when rate limiting is disabled.
+func rateLimit(store *repository.Store, cfg *config.Config, name string, policy config.RateLimitPolicy) gin.HandlerFunc {
+	if !cfg.RateLimit.Enabled {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return middleware.RateLimit(store.RateLimits, name, policy)
+}
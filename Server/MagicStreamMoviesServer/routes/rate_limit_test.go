@@ -0,0 +1,81 @@
+package routes
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/config"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/fixtures"
+	model "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+)
+
+func TestRateLimitHeaders(t *testing.T) {
+	s := newTestServer(t, fixtures.Set{Genres: testGenres}, func(cfg *config.Config) {
+		cfg.RateLimit.Public = config.RateLimitPolicy{Limit: 3, Window: time.Minute}
+	})
+
+	for remaining := 2; remaining >= 0; remaining-- {
+		recorder := s.do(http.MethodGet, "/genres", "")
+		expectStatus(t, recorder, http.StatusOK)
+		header := recorder.Header()
+		if got := header.Get("RateLimit-Limit"); got != "3" {
+			t.Errorf("RateLimit-Limit %q, want 3", got)
+		}
+		if got := header.Get("RateLimit-Remaining"); got != strconv.Itoa(remaining) {
+			t.Errorf("RateLimit-Remaining %q, want %d", got, remaining)
+		}
+		if got := header.Get("RateLimit-Policy"); got != "3;w=60" {
+			t.Errorf("RateLimit-Policy %q, want 3;w=60", got)
+		}
+		if reset, err := strconv.Atoi(header.Get("RateLimit-Reset")); err != nil || reset < 1 || reset > 60 {
+			t.Errorf("RateLimit-Reset %q, want 1 to 60 seconds", header.Get("RateLimit-Reset"))
+		}
+	}
+
+	recorder := s.do(http.MethodGet, "/genres", "")
+	expectStatus(t, recorder, http.StatusTooManyRequests)
+	// One token comes back every 20 seconds
+	if retry, err := strconv.Atoi(recorder.Header().Get("Retry-After")); err != nil || retry < 1 || retry > 20 {
+		t.Errorf("Retry-After %q, want 1 to 20 seconds", recorder.Header().Get("Retry-After"))
+	}
+	if got := recorder.Header().Get("RateLimit-Remaining"); got != "0" {
+		t.Errorf("refused request has RateLimit-Remaining %q, want 0", got)
+	}
+}
+
+func TestRateLimitKeysSignedInUsersByID(t *testing.T) {
+	seed := fixtures.Set{Users: []model.User{testUser(t, "alice", "USER"), testUser(t, "bob", "USER")}}
+	s := newTestServer(t, seed, func(cfg *config.Config) {
+		cfg.RateLimit.API = config.RateLimitPolicy{Limit: 2, Window: time.Minute}
+	})
+	alice := s.login(t, "alice")
+	bob := s.login(t, "bob")
+
+	expectStatus(t, s.do(http.MethodGet, "/me", "", bearer(alice.Token)...), http.StatusOK)
+	expectStatus(t, s.do(http.MethodGet, "/me", "", bearer(alice.Token)...), http.StatusOK)
+	expectStatus(t, s.do(http.MethodGet, "/me", "", bearer(alice.Token)...), http.StatusTooManyRequests)
+
+	// Same IP, different user
+	recorder := s.do(http.MethodGet, "/me", "", bearer(bob.Token)...)
+	expectStatus(t, recorder, http.StatusOK)
+	if got := recorder.Header().Get("RateLimit-Remaining"); got != "1" {
+		t.Errorf("bob's RateLimit-Remaining %q, want 1", got)
+	}
+}
+
+func TestRateLimitDisabled(t *testing.T) {
+	s := newTestServer(t, fixtures.Set{Genres: testGenres}, func(cfg *config.Config) {
+		cfg.RateLimit.Enabled = false
+		cfg.RateLimit.Public = config.RateLimitPolicy{Limit: 1, Window: time.Minute}
+	})
+
+	for i := 0; i < 3; i++ {
+		recorder := s.do(http.MethodGet, "/genres", "")
+		expectStatus(t, recorder, http.StatusOK)
+		if got := recorder.Header().Get("RateLimit-Limit"); got != "" {
+			t.Fatalf("RateLimit-Limit %q with rate limiting disabled", got)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package routes
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/config"
+	model "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+)
+
+func TestRatingsUpdateAudienceScore(t *testing.T) {
+	seed := catalogue()
+	seed.Users = []model.User{testUser(t, "u1", "USER"), testUser(t, "u2", "USER")}
+	s := newTestServer(t, seed, func(cfg *config.Config) { cfg.RateLimit.Enabled = false })
+	u1 := s.login(t, "u1")
+	u2 := s.login(t, "u2")
+
+	expectStatus(t, s.do(http.MethodPut, "/movies/tt0000001/rating", `{"stars":3}`, bearer(u1.Token)...), http.StatusCreated)
+	expectStatus(t, s.do(http.MethodPut, "/movies/tt0000001/rating", `{"stars":4}`, bearer(u2.Token)...), http.StatusCreated)
+	// A second rating by the same user replaces the first
+	expectStatus(t, s.do(http.MethodPut, "/movies/tt0000001/rating", `{"stars":5}`, bearer(u1.Token)...), http.StatusOK)
+
+	var movie model.Movie
+	decode(t, s.do(http.MethodGet, "/movie/tt0000001", "", bearer(u1.Token)...), &movie)
+	if movie.AudienceScore != 4.5 || movie.RatingCount != 2 {
+		t.Errorf("audience score %v from %d ratings, want 4.5 from 2", movie.AudienceScore, movie.RatingCount)
+	}
+
+	expectStatus(t, s.do(http.MethodDelete, "/movies/tt0000001/rating", "", bearer(u1.Token)...), http.StatusNoContent)
+	decode(t, s.do(http.MethodGet, "/movie/tt0000001", "", bearer(u1.Token)...), &movie)
+	if movie.AudienceScore != 4 || movie.RatingCount != 1 {
+		t.Errorf("after a deletion audience score %v from %d ratings, want 4 from 1", movie.AudienceScore, movie.RatingCount)
+	}
+
+	expectStatus(t, s.do(http.MethodDelete, "/movies/tt0000001/rating", "", bearer(u1.Token)...), http.StatusNotFound)
+	expectStatus(t, s.do(http.MethodPut, "/movies/tt9999999/rating", `{"stars":5}`, bearer(u1.Token)...), http.StatusNotFound)
+	expectStatus(t, s.do(http.MethodPut, "/movies/tt0000001/rating", `{"stars":6}`, bearer(u1.Token)...), http.StatusBadRequest)
+}
+
+func TestMovieRatingsNewestFirst(t *testing.T) {
+	seed := catalogue()
+	seed.Users = []model.User{testUser(t, "u1", "USER"), testUser(t, "u2", "USER"), testUser(t, "u3", "USER")}
+	s := newTestServer(t, seed, func(cfg *config.Config) { cfg.RateLimit.Enabled = false })
+	for _, id := range []string{"u1", "u2", "u3"} {
+		user := s.login(t, id)
+		expectStatus(t, s.do(http.MethodPut, "/movies/tt0000001/rating", `{"stars":4}`, bearer(user.Token)...), http.StatusCreated)
+	}
+
+	var first model.Page[model.Rating]
+	decode(t, s.do(http.MethodGet, "/movies/tt0000001/ratings?limit=2", ""), &first)
+	if len(first.Items) != 2 || first.Total != 3 || first.NextCursor == "" {
+		t.Fatalf("first page %d of %d ratings, cursor %q", len(first.Items), first.Total, first.NextCursor)
+	}
+	if first.Items[0].UserID != "u3" || first.Items[1].UserID != "u2" {
+		t.Errorf("first page rated by %s and %s, want u3 and u2", first.Items[0].UserID, first.Items[1].UserID)
+	}
+
+	var second model.Page[model.Rating]
+	decode(t, s.do(http.MethodGet, "/movies/tt0000001/ratings?limit=2&cursor="+url.QueryEscape(first.NextCursor), ""), &second)
+	if len(second.Items) != 1 || second.Items[0].UserID != "u1" || second.NextCursor != "" {
+		t.Errorf("second page %+v, cursor %q, want only u1's rating", second.Items, second.NextCursor)
+	}
+}
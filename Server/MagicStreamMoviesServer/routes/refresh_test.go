@@ -0,0 +1,84 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/fixtures"
+	model "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+)
+
+// tokenPair is the token and refresh_token of a /refresh response.
+type tokenPair struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (s *testServer) refresh(refreshToken string) *httptest.ResponseRecorder {
+	return s.do(http.MethodPost, "/refresh", `{"refresh_token":"`+refreshToken+`"}`)
+}
+
+func TestRefreshRotatesTheRefreshToken(t *testing.T) {
+	s := newTestServer(t, fixtures.Set{Users: []model.User{testUser(t, "alice", "USER")}}, nil)
+	first := s.login(t, "alice")
+
+	recorder := s.refresh(first.RefreshToken)
+	expectStatus(t, recorder, http.StatusOK)
+	var second tokenPair
+	decode(t, recorder, &second)
+	if second.RefreshToken == first.RefreshToken || second.Token == first.Token {
+		t.Fatal("refresh returned the same tokens")
+	}
+
+	expectStatus(t, s.do(http.MethodGet, "/me", "", bearer(second.Token)...), http.StatusOK)
+	expectStatus(t, s.refresh(second.RefreshToken), http.StatusOK)
+	// An access token is not a refresh token
+	expectStatus(t, s.refresh(second.Token), http.StatusUnauthorized)
+}
+
+func TestRefreshTokenReuseSignsOutTheSession(t *testing.T) {
+	s := newTestServer(t, fixtures.Set{Users: []model.User{testUser(t, "alice", "USER")}}, nil)
+	stolen := s.login(t, "alice")
+	other := s.login(t, "alice")
+
+	recorder := s.refresh(stolen.RefreshToken)
+	expectStatus(t, recorder, http.StatusOK)
+	var rotated tokenPair
+	decode(t, recorder, &rotated)
+
+	// Presenting the rotated-out token again means two parties hold the
+	// session, so the whole family goes
+	expectStatus(t, s.refresh(stolen.RefreshToken), http.StatusUnauthorized)
+	expectStatus(t, s.refresh(rotated.RefreshToken), http.StatusUnauthorized)
+	expectStatus(t, s.do(http.MethodGet, "/me", "", bearer(rotated.Token)...), http.StatusUnauthorized)
+
+	// The user's other session is not part of the family
+	expectStatus(t, s.do(http.MethodGet, "/me", "", bearer(other.Token)...), http.StatusOK)
+	expectStatus(t, s.refresh(other.RefreshToken), http.StatusOK)
+}
+
+func TestDeleteSessionSignsOutAnotherDevice(t *testing.T) {
+	s := newTestServer(t, fixtures.Set{Users: []model.User{testUser(t, "alice", "USER")}}, nil)
+	phone := s.login(t, "alice")
+	laptop := s.login(t, "alice")
+
+	var listing struct {
+		Sessions []model.SessionResponse `json:"sessions"`
+	}
+	decode(t, s.do(http.MethodGet, "/me/sessions", "", bearer(laptop.Token)...), &listing)
+	if len(listing.Sessions) != 2 {
+		t.Fatalf("%d sessions, want 2", len(listing.Sessions))
+	}
+	phoneSession := ""
+	for _, session := range listing.Sessions {
+		if !session.Current {
+			phoneSession = session.SessionID
+		}
+	}
+
+	expectStatus(t, s.do(http.MethodDelete, "/me/sessions/"+phoneSession, "", bearer(laptop.Token)...), http.StatusNoContent)
+	expectStatus(t, s.do(http.MethodGet, "/me", "", bearer(phone.Token)...), http.StatusUnauthorized)
+	expectStatus(t, s.refresh(phone.RefreshToken), http.StatusUnauthorized)
+	expectStatus(t, s.do(http.MethodGet, "/me", "", bearer(laptop.Token)...), http.StatusOK)
+}
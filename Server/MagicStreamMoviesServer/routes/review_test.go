@@ -0,0 +1,174 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/ai"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/config"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/fixtures"
+	model "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+)
+
+// terribleReview is one the lexicon ranks Terrible.
+const terribleReview = "Absolutely terrible, the worst film I have seen."
+
+// chatStub is an OpenAI-compatible chat completions server that answers every
+// request with status and, on 200, answer.
+type chatStub struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	status   int
+	answer   string
+	requests []*http.Request
+	bodies   []map[string]interface{}
+}
+
+func newChatStub(t *testing.T, status int, answer string) *chatStub {
+	stub := &chatStub{status: status, answer: answer}
+	stub.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		stub.mu.Lock()
+		stub.requests = append(stub.requests, r)
+		stub.bodies = append(stub.bodies, body)
+		stub.mu.Unlock()
+
+		if r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		if stub.status != http.StatusOK {
+			http.Error(w, "unavailable", stub.status)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []interface{}{
+				map[string]interface{}{"message": map[string]string{"role": "assistant", "content": stub.answer}},
+			},
+		})
+	}))
+	t.Cleanup(stub.Close)
+	return stub
+}
+
+// calls returns how many requests the stub has answered.
+func (s *chatStub) calls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.requests)
+}
+
+// reviewServer is the API with a movie to review and the classifier set up
+// by configure.
+func reviewServer(t *testing.T, configure func(cfg *config.Config)) (*testServer, model.UserResponse) {
+	rankings := append([]model.Ranking{{RankingValue: ai.NotRankedValue, RankingName: "Not_Ranked"}}, ai.DefaultRankings...)
+	s := newTestServer(t, fixtures.Set{
+		Genres:   testGenres,
+		Rankings: rankings,
+		Movies:   []model.Movie{testMovie("tt0000001", "Reviewed", testGenres[0])},
+		Users:    []model.User{testUser(t, "admin", "ADMIN")},
+	}, configure)
+	return s, s.login(t, "admin")
+}
+
+// review sets the admin review of the movie and returns the ranking given.
+func review(t *testing.T, s *testServer, admin model.UserResponse, text string) model.Ranking {
+	t.Helper()
+	body, _ := json.Marshal(map[string]string{"admin_review": text})
+	recorder := s.do(http.MethodPatch, "/updatereview/tt0000001", string(body), bearer(admin.Token)...)
+	expectStatus(t, recorder, http.StatusOK)
+
+	var response struct {
+		Movie model.Movie `json:"movie"`
+	}
+	decode(t, recorder, &response)
+	return response.Movie.Ranking
+}
+
+func openAIClassifier(stub *chatStub) func(cfg *config.Config) {
+	return func(cfg *config.Config) {
+		cfg.Classifier = config.Classifier{
+			Backend:       "openai",
+			OpenAIAPIKey:  "test-key",
+			OpenAIBaseURL: stub.URL + "/v1",
+			OpenAIModel:   "test-model",
+		}
+	}
+}
+
+func TestReviewRankedByOpenAI(t *testing.T) {
+	stub := newChatStub(t, http.StatusOK, "Good.")
+	s, admin := reviewServer(t, openAIClassifier(stub))
+
+	// The model's answer wins over what the lexicon would say
+	if ranking := review(t, s, admin, terribleReview); ranking.RankingName != "Good" || ranking.RankingValue != 2 {
+		t.Errorf("ranking %+v, want Good", ranking)
+	}
+	if stub.calls() != 1 {
+		t.Fatalf("%d chat completions requests, want 1", stub.calls())
+	}
+	if got := stub.requests[0].Header.Get("Authorization"); got != "Bearer test-key" {
+		t.Errorf("Authorization %q", got)
+	}
+	if got := stub.bodies[0]["model"]; got != "test-model" {
+		t.Errorf("model %v, want test-model", got)
+	}
+}
+
+func TestReviewFallsBackToLexicon(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		answer string
+	}{
+		{"server error", http.StatusInternalServerError, ""},
+		{"unknown ranking", http.StatusOK, "I would rather not say"},
+		// The answers contain Okay, but do not say it
+		{"negated ranking", http.StatusOK, "not okay"},
+		{"ranking in a sentence", http.StatusOK, "Not Ranked okay"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stub := newChatStub(t, test.status, test.answer)
+			s, admin := reviewServer(t, openAIClassifier(stub))
+
+			if ranking := review(t, s, admin, terribleReview); ranking.RankingName != "Terrible" {
+				t.Errorf("ranking %+v, want the lexicon's Terrible", ranking)
+			}
+			if stub.calls() != 1 {
+				t.Errorf("%d chat completions requests, want 1", stub.calls())
+			}
+		})
+	}
+}
+
+func TestReviewUnreachableOpenAIFallsBackToLexicon(t *testing.T) {
+	stub := newChatStub(t, http.StatusOK, "Good")
+	stub.Close()
+	s, admin := reviewServer(t, openAIClassifier(stub))
+
+	if ranking := review(t, s, admin, terribleReview); ranking.RankingName != "Terrible" {
+		t.Errorf("ranking %+v, want the lexicon's Terrible", ranking)
+	}
+}
+
+func TestReviewLexiconBackendSkipsOpenAI(t *testing.T) {
+	stub := newChatStub(t, http.StatusOK, "Good")
+	s, admin := reviewServer(t, func(cfg *config.Config) {
+		openAIClassifier(stub)(cfg)
+		cfg.Classifier.Backend = "lexicon"
+	})
+
+	if ranking := review(t, s, admin, terribleReview); ranking.RankingName != "Terrible" {
+		t.Errorf("ranking %+v, want the lexicon's Terrible", ranking)
+	}
+	if stub.calls() != 0 {
+		t.Errorf("%d chat completions requests with the lexicon backend", stub.calls())
+	}
+}
@@ -0,0 +1,41 @@
+package routes
+
+import (
+	"log"
+	"time"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/config"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/middleware"
+)
+
+// NewRouter returns the engine with the middleware every route shares. The
+// client IP is only taken from forwarding headers sent by
+// cfg.Server.TrustedProxies, so clients cannot choose the IP the rate limits
+// and login throttling count them by.
+func NewRouter(cfg *config.Config) (*gin.Engine, error) {
+	router := gin.Default()
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		return nil, err
+	}
+
+	// CORS Configuration
+	for _, origin := range cfg.Server.AllowedOrigins {
+		log.Println("Allowed Origin:", origin)
+	}
+
+	corsConfig := cors.Config{
+		AllowOrigins:     cfg.Server.AllowedOrigins,
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
+		ExposeHeaders:    []string{"Content-Length", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After"},
+		AllowCredentials: true,
+		MaxAge:           12 * time.Hour,
+	}
+
+	router.Use(cors.New(corsConfig))
+	router.Use(gin.Logger())
+	router.Use(middleware.RequestTimeout(cfg.Server.RequestTimeout))
+	return router, nil
+}
@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/fixtures"
+	model "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
+)
+
+func TestPasswordChangeRevokesTokensFromTheSameSecond(t *testing.T) {
+	s := newTestServer(t, fixtures.Set{Users: []model.User{testUser(t, "alice", "USER")}}, nil)
+
+	// The token is issued a moment before the change, almost always in the
+	// same second, which a cutoff truncated to the second would spare
+	old := s.login(t, "alice")
+	recorder := s.do(http.MethodPatch, "/me", `{"new_password":"changed-pw1","current_password":"`+testPassword+`"}`, bearer(old.Token)...)
+	expectStatus(t, recorder, http.StatusOK)
+	var changed struct {
+		Token string `json:"token"`
+	}
+	decode(t, recorder, &changed)
+
+	expectStatus(t, s.do(http.MethodGet, "/me", "", bearer(old.Token)...), http.StatusUnauthorized)
+	expectStatus(t, s.do(http.MethodGet, "/me", "", bearer(changed.Token)...), http.StatusOK)
+}
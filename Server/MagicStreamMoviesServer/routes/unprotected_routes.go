@@ -2,17 +2,38 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/config"
 	controller "github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/controllers"
-	"go.mongodb.org/mongo-driver/mongo"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/loginguard"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/mailer"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/repository"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/utils"
 )
 
-func SetupUnProtectedRoutes(router *gin.Engine, client *mongo.Client) {
-	
-	// Public routes (no authentication)
-	router.GET("/movies", controller.GetMovies())
-	router.POST("/register", controller.RegisterUser())
-	router.POST("/login", controller.LoginUser(client))
-	router.POST("/logout", controller.LogoutHandler(client))
-	router.GET("/genres", controller.GetGenres(client))
-	router.POST("/refresh", controller.RefreshTokenHandler(client))
+func SetupUnProtectedRoutes(router *gin.Engine, store *repository.Store, cfg *config.Config, tokens *utils.Tokens) {
+	mail := mailer.New(cfg.Mail)
+	guard := loginguard.New(store.Logins, store.Users, cfg.Login)
+
+	// Public routes (no authentication). The catalogue and the endpoints
+	// that check credentials or send mail are throttled separately, per IP.
+	public := router.Group("/", rateLimit(store, cfg, "public", cfg.RateLimit.Public))
+	{
+		public.GET("/movies", controller.GetMovies(store.Movies))
+		public.GET("/movies/search", controller.SearchMovies(store.Movies))
+		public.GET("/movies/:imdb_id/ratings", controller.GetMovieRatings(store.Ratings))
+		public.GET("/genres", controller.GetGenres(store.Genres))
+		public.GET("/.well-known/jwks.json", controller.GetJWKS(tokens.Keys))
+	}
+
+	auth := router.Group("/", rateLimit(store, cfg, "auth", cfg.RateLimit.Auth))
+	{
+		auth.POST("/register", controller.RegisterUser(store.Users, store.Genres, store.UserTokens, mail, cfg.Account))
+		auth.POST("/login", controller.LoginUser(store.Users, store.Sessions, tokens, guard, cfg.Account))
+		auth.POST("/logout", controller.LogoutHandler(store.Sessions, store.Revocations, tokens))
+		auth.POST("/refresh", controller.RefreshTokenHandler(store.Users, store.Sessions, store.Revocations, tokens))
+		auth.GET("/verify-email", controller.VerifyEmail(store.Users, store.UserTokens))
+		auth.POST("/verify-email/resend", controller.ResendVerification(store.Users, store.UserTokens, mail, cfg.Account))
+		auth.POST("/password/forgot", controller.RequestPasswordReset(store.Users, store.UserTokens, mail, cfg.Account))
+		auth.POST("/password/reset", controller.ResetPassword(store.Users, store.UserTokens, store.Sessions, store.Revocations, tokens))
+	}
 }
\ No newline at end of file
@@ -0,0 +1,72 @@
+package search
+
+import (
+	"html"
+	"strings"
+)
+
+const (
+	markOpen  = "<mark>"
+	markClose = "</mark>"
+)
+
+// Highlight returns a snippet of text around the first word matching one of
+// the query terms, with every matching word wrapped in <mark> tags. At most
+// window words are kept on either side of the first match. The boolean is
+// false when nothing in text matches.
+//
+// The snippet is HTML: the text between the tags is escaped, so a review
+// containing markup is shown as written rather than rendered.
+func Highlight(text string, terms []string, window int) (string, bool) {
+	tokens := tokenize(text)
+
+	matched := make([]bool, len(tokens))
+	first := -1
+	for i, t := range tokens {
+		for _, term := range terms {
+			if matchWeight(t.norm, term) > 0 {
+				matched[i] = true
+				break
+			}
+		}
+		if matched[i] && first < 0 {
+			first = i
+		}
+	}
+	if first < 0 {
+		return "", false
+	}
+
+	from := first - window
+	if from < 0 {
+		from = 0
+	}
+	to := first + window + 1
+	if to > len(tokens) {
+		to = len(tokens)
+	}
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("…")
+	}
+	pos := tokens[from].start
+	for i := from; i < to; i++ {
+		t := tokens[i]
+		b.WriteString(html.EscapeString(text[pos:t.start]))
+		if matched[i] {
+			b.WriteString(markOpen)
+			b.WriteString(html.EscapeString(text[t.start:t.end]))
+			b.WriteString(markClose)
+		} else {
+			b.WriteString(html.EscapeString(text[t.start:t.end]))
+		}
+		pos = t.end
+	}
+	if to < len(tokens) {
+		b.WriteString("…")
+	} else {
+		b.WriteString(html.EscapeString(text[pos:]))
+	}
+	return b.String(), true
+}
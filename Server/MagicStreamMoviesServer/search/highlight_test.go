@@ -0,0 +1,45 @@
+package search
+
+import "testing"
+
+func TestHighlightEscapesText(t *testing.T) {
+	review := `Great <script>alert("x")</script> film & a great cast`
+	got, ok := Highlight(review, Terms("great script"), 20)
+	if !ok {
+		t.Fatal("no match")
+	}
+	want := `<mark>Great</mark> &lt;<mark>script</mark>&gt;alert(&#34;x&#34;)&lt;/<mark>script</mark>&gt; film &amp; a <mark>great</mark> cast`
+	if got != want {
+		t.Errorf("Highlight =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestHighlightWindow(t *testing.T) {
+	got, ok := Highlight("one two <b>three</b> four five", []string{"three"}, 2)
+	if !ok {
+		t.Fatal("no match")
+	}
+	if want := "…two &lt;b&gt;<mark>three</mark>&lt;/b&gt; four…"; got != want {
+		t.Errorf("Highlight = %q, want %q", got, want)
+	}
+}
+
+func TestHighlightNoMatch(t *testing.T) {
+	if _, ok := Highlight("<script>", []string{"mark"}, 5); ok {
+		t.Error("matched text without the term")
+	}
+}
+
+func TestHighlightStemmedForms(t *testing.T) {
+	// A term marks the longer forms it is a prefix of, not shorter ones
+	got, ok := Highlight("Two films by a filmmaker", Terms("film"), 20)
+	if !ok {
+		t.Fatal("no match")
+	}
+	if want := "Two <mark>films</mark> by a <mark>filmmaker</mark>"; got != want {
+		t.Errorf("Highlight = %q, want %q", got, want)
+	}
+	if got, ok := Highlight("One film", Terms("films"), 20); ok {
+		t.Errorf("Highlight = %q, want no match for the singular", got)
+	}
+}
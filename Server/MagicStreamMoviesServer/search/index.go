@@ -0,0 +1,108 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// Document is a unit of text to be indexed. Fields maps a field name to its
+// text; each field contributes to the score according to the weight the index
+// was created with.
+type Document struct {
+	ID     string
+	Fields map[string]string
+}
+
+// Hit is a scored search result.
+type Hit struct {
+	ID    string
+	Score float64
+}
+
+// Index is an in-process inverted index used when the database has no text
+// index. It is safe for concurrent use.
+type Index struct {
+	mu       sync.RWMutex
+	weights  map[string]float64
+	postings map[string]map[string]float64
+	docCount int
+	built    bool
+	revision uint64
+}
+
+// NewIndex creates an empty index. Fields missing from weights get a weight
+// of 1.
+func NewIndex(weights map[string]float64) *Index {
+	return &Index{weights: weights, postings: map[string]map[string]float64{}}
+}
+
+// Stale reports whether the index has never been built or was built from
+// an older revision of the documents.
+func (idx *Index) Stale(revision uint64) bool {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return !idx.built || idx.revision != revision
+}
+
+// Rebuild replaces the contents of the index with docs, which were loaded
+// at the given revision. Read the revision before loading the documents so a
+// write that races with the rebuild leaves the index stale.
+func (idx *Index) Rebuild(docs []Document, revision uint64) {
+	postings := map[string]map[string]float64{}
+	for _, doc := range docs {
+		for field, text := range doc.Fields {
+			weight, ok := idx.weights[field]
+			if !ok {
+				weight = 1
+			}
+			for _, t := range tokenize(text) {
+				if postings[t.norm] == nil {
+					postings[t.norm] = map[string]float64{}
+				}
+				postings[t.norm][doc.ID] += weight
+			}
+		}
+	}
+
+	idx.mu.Lock()
+	idx.postings = postings
+	idx.docCount = len(docs)
+	idx.built = true
+	idx.revision = revision
+	idx.mu.Unlock()
+}
+
+// Search scores every document against the query terms using a weighted
+// TF-IDF and returns the matches best first. Ties are broken by ID so the
+// order is stable across calls.
+func (idx *Index) Search(terms []string) []Hit {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	scores := map[string]float64{}
+	for _, term := range terms {
+		for word, docs := range idx.postings {
+			match := matchWeight(word, term)
+			if match == 0 {
+				continue
+			}
+			idf := math.Log(1 + float64(idx.docCount)/float64(len(docs)))
+			for id, tf := range docs {
+				scores[id] += match * tf * idf
+			}
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{ID: id, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	return hits
+}
@@ -0,0 +1,84 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalize lower-cases s and strips diacritics so that "Amélie" and "amelie"
+// compare equal.
+func Normalize(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range norm.NFD.String(s) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// token is a word in the original text together with its byte offsets and
+// normalised form.
+type token struct {
+	start, end int
+	norm       string
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// tokenize splits text into words, keeping the offsets into the original
+// string so matches can be highlighted in place.
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range text {
+		if isWordRune(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			tokens = append(tokens, token{start: start, end: i, norm: Normalize(text[start:i])})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{start: start, end: len(text), norm: Normalize(text[start:])})
+	}
+	return tokens
+}
+
+// Terms returns the distinct normalised words of a query in the order they
+// first appear.
+func Terms(query string) []string {
+	seen := map[string]bool{}
+	var terms []string
+	for _, t := range tokenize(query) {
+		if !seen[t.norm] {
+			seen[t.norm] = true
+			terms = append(terms, t.norm)
+		}
+	}
+	return terms
+}
+
+// matchWeight reports how well an indexed word matches a query term: 1 for an
+// exact match, 0.5 when the word merely starts with the term, 0 otherwise.
+// Prefix matches are limited to terms of three or more characters so short
+// queries do not match half the catalogue.
+func matchWeight(word, term string) float64 {
+	if word == term {
+		return 1
+	}
+	if len(term) >= 3 && strings.HasPrefix(word, term) {
+		return 0.5
+	}
+	return 0
+}
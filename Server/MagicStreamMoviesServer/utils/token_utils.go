@@ -1,16 +1,18 @@
 package utils
 
 import (
-	"os"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
 	"time"
-	"context"
 	"errors"
+	"fmt"
 
 	jwt "github.com/golang-jwt/jwt/v5"
-	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/database"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/config"
+	"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/jwks"
 	//"github.com/siddharthX6174/MagicStreamMovies/Server/MagicStreamMoviesServer/models"
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
 	"github.com/gin-gonic/gin"
 )
 
@@ -20,39 +22,81 @@ type SignedDetails struct {
 	LastName  string
 	Role      string
 	UserID    string
+	// SessionID is the session family the tokens were issued to.
+	SessionID string
+	// TokenUse is "access" or "refresh". Both kinds are signed with the same
+	// keys, so this is what stops a refresh token being used as an access
+	// token.
+	TokenUse string `json:"token_use"`
 	jwt.RegisteredClaims
 }
 
-var SECRET_KEY string = os.Getenv("SECRET_KEY")
-var SECRET_REFRESH_KEY string = os.Getenv("SECRET_REFRESH_KEY")
+const (
+	accessToken  = "access"
+	refreshToken = "refresh"
+	issuer       = "MagicStream"
+)
 
-// Set default values if environment variables are not set
-func init() {
-	if SECRET_KEY == "" {
-		SECRET_KEY = "your-secret-key-here-change-in-production"
-	}
-	if SECRET_REFRESH_KEY == "" {
-		SECRET_REFRESH_KEY = "your-refresh-secret-key-here-change-in-production"
+// Tokens issues and verifies the server's JWTs, signed with the key set's
+// signing key and verified with whichever key the kid header names.
+type Tokens struct {
+	Keys       *jwks.KeySet
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+}
+
+// NewTokens returns a Tokens using keys and the lifetimes in auth.
+func NewTokens(keys *jwks.KeySet, auth config.Auth) *Tokens {
+	return &Tokens{Keys: keys, AccessTTL: auth.AccessTokenTTL, RefreshTTL: auth.RefreshTokenTTL}
+}
+
+func signingMethod(algorithm string) jwt.SigningMethod {
+	if algorithm == jwks.EdDSA {
+		return jwt.SigningMethodEdDSA
 	}
+	return jwt.SigningMethodRS256
+}
+
+// NewTokenID returns a random identifier for a token or session.
+func NewTokenID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// HashToken returns the SHA-256 of a token, which is what gets stored so a
+// leaked database does not leak usable tokens.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
-//var userCollection *mongo.Collection = database.OpenCollection("users", client)
 
+func (t *Tokens) sign(claims *SignedDetails) (string, error) {
+	key := t.Keys.SigningKey()
+	token := jwt.NewWithClaims(signingMethod(key.Algorithm), claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Private)
+}
 
-func GenerateAllTokens(email, firstName, lastName, role, userId string) (string, string, error) {
+func (t *Tokens) GenerateAllTokens(email, firstName, lastName, role, userId, sessionID string) (string, string, error) {
+	now := time.Now()
 	claims := &SignedDetails{
 		Email:     email,
 		FirstName: firstName,
 		LastName:  lastName,
 		Role:      role,
 		UserID:    userId,
+		SessionID: sessionID,
+		TokenUse:  accessToken,
 		RegisteredClaims: jwt.RegisteredClaims{
-			Issuer:    "MagicStream",
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			// The jti lets this one token be revoked before it expires.
+			ID:        NewTokenID(),
+			Issuer:    issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(t.AccessTTL)),
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString([]byte(SECRET_KEY))
+	signedToken, err := t.sign(claims)
 
 	if err != nil {
 		return "", "", err
@@ -64,14 +108,17 @@ func GenerateAllTokens(email, firstName, lastName, role, userId string) (string,
 		LastName:  lastName,
 		Role:      role,
 		UserID:    userId,
+		SessionID: sessionID,
+		TokenUse:  refreshToken,
 		RegisteredClaims: jwt.RegisteredClaims{
-			Issuer:    "MagicStream",
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * 7 * time.Hour)),
+			// A fresh ID keeps every refresh token, and so its hash, unique.
+			ID:        NewTokenID(),
+			Issuer:    issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(t.RefreshTTL)),
 		},
 	}
-	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
-	signedRefreshToken, err := refreshToken.SignedString([]byte(SECRET_REFRESH_KEY))
+	signedRefreshToken, err := t.sign(refreshClaims)
 
 	if err != nil {
 		return "", "", err
@@ -82,38 +129,14 @@ func GenerateAllTokens(email, firstName, lastName, role, userId string) (string,
 }
 
 
-func UpdateAllTokens(userId, token, refreshToken string, client *mongo.Client) (err error) {
-	var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
-	defer cancel()
-
-	updateAt, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-
-	updateData := bson.M{
-		"$set": bson.M{
-			"token":         token,
-			"refresh_token": refreshToken,
-			"update_at":     updateAt,
-		},
-	}
-
-	var userCollection *mongo.Collection = database.OpenCollection("users", client)
-
-	_, err = userCollection.UpdateOne(ctx, bson.M{"user_id": userId}, updateData)
-
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
 func GetAccessToken(c *gin.Context) (string, error) {
 	authHeader := c.Request.Header.Get("Authorization")
 	if authHeader == "" {
 		return "", errors.New("authorization header is required")
 	}
-	tokenString := authHeader[len("Bearer "):]
+	tokenString, ok := strings.CutPrefix(authHeader, "Bearer ")
 
-	if tokenString == "" {
+	if !ok || tokenString == "" {
 		return "", errors.New("bearer token is required")
 	}
 	
@@ -128,45 +151,41 @@ func GetAccessToken(c *gin.Context) (string, error) {
 }
 
 
-func ValidateToken(tokenString string) (*SignedDetails, error) {
+// parse verifies a token against the key its kid names. The algorithm is
+// pinned to that key's, so a token cannot pick a weaker one or smuggle in an
+// HMAC signature keyed with the public key.
+func (t *Tokens) parse(tokenString, use string) (*SignedDetails, error) {
 	claims := &SignedDetails{}
 
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return []byte(SECRET_KEY), nil
-	})
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		found, ok := t.Keys.Lookup(kid)
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		if token.Method.Alg() != found.Algorithm {
+			return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+		}
+		return found.Public, nil
+	},
+		jwt.WithValidMethods([]string{jwks.RS256, jwks.EdDSA}),
+		jwt.WithIssuer(issuer),
+		jwt.WithExpirationRequired(),
+	)
 	if err != nil {
 		return nil, err
 	}
-
-	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-		return nil, err
-	}
-
-	if claims.ExpiresAt.Time.Before(time.Now()) {
-		return nil, errors.New("token has expired")
+	if claims.TokenUse != use {
+		return nil, errors.New("wrong kind of token")
 	}
 
 	return claims, nil
-
 }
 
-func ValidateRefreshToken(tokenString string) (*SignedDetails, error) {
-	claims := &SignedDetails{}
-
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return []byte(SECRET_REFRESH_KEY), nil
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-		return nil, err
-	}
-
-	if claims.ExpiresAt.Time.Before(time.Now()) {
-		return nil, errors.New("refresh token has expired")
-	}
+func (t *Tokens) ValidateToken(tokenString string) (*SignedDetails, error) {
+	return t.parse(tokenString, accessToken)
+}
 
-	return claims, nil
-}
\ No newline at end of file
+func (t *Tokens) ValidateRefreshToken(tokenString string) (*SignedDetails, error) {
+	return t.parse(tokenString, refreshToken)
+}